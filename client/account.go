@@ -0,0 +1,42 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"fmt"
+)
+
+// Account is the account of the remote platform in config.
+type Account struct {
+	// Name is the name of account in config. ex) GitLab #1 (https://gitlab.com/api/v4)
+	Name string
+
+	// Client is the GitClient of account (not wrapped with the cache).
+	Client GitClient
+
+	// Err is the error of Init. It is nil if the account is not initialized yet.
+	Err error
+}
+
+// AccountError is the error of the account.
+type AccountError struct {
+	Account string
+	Err     error
+}
+
+// Error
+func (e *AccountError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Account, e.Err)
+}
+
+// Unwrap
+func (e *AccountError) Unwrap() error {
+	return e.Err
+}
+
+// getAccountName
+func getAccountName(kind string, i int, target string) string {
+	return fmt.Sprintf("%s #%d (%s)", kind, i+1, target)
+}
@@ -0,0 +1,530 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BitbucketClient is the client of Bitbucket Cloud Snippets.
+type BitbucketClient struct {
+	client       *http.Client
+	Url          string
+	User         string
+	PlatformName string
+	FilterKey    string
+
+	// Workspace is the workspace slug to create snippet. If empty, snippets of the user are used.
+	Workspace string
+
+	// auth. if authUser is set, token is used as App password.
+	authUser string
+	token    string
+
+	// ssl, proxy and retry
+	httpOption httpOption
+}
+
+var (
+	BitbucketIsPrivate = Visibility{code: "private", num: 0}
+	BitbucketIsPublic  = Visibility{code: "public", num: 1}
+)
+
+// bitbucketConcurrency is the number of snippets fetched at the same time.
+const bitbucketConcurrency = 8
+
+// bitbucketLink
+type bitbucketLink struct {
+	Href string `json:"href"`
+}
+
+// bitbucketSnippet
+type bitbucketSnippet struct {
+	Id        string    `json:"id"`
+	Title     string    `json:"title"`
+	IsPrivate bool      `json:"is_private"`
+	CreatedOn time.Time `json:"created_on"`
+	UpdatedOn time.Time `json:"updated_on"`
+	Links     struct {
+		Self bitbucketLink `json:"self"`
+		HTML bitbucketLink `json:"html"`
+	} `json:"links"`
+	Files map[string]struct {
+		Links struct {
+			Self bitbucketLink `json:"self"`
+			HTML bitbucketLink `json:"html"`
+		} `json:"links"`
+	} `json:"files"`
+}
+
+// Init
+func (b *BitbucketClient) Init(ctx context.Context, u, user, token string) (err error) {
+	// Create http Client
+	b.client, err = newHTTPClient(b.httpOption)
+	if err != nil {
+		return
+	}
+
+	b.Url = strings.TrimSuffix(u, "/")
+	b.authUser = user
+	b.token = token
+
+	// get login user
+	var login struct {
+		Username string `json:"username"`
+		Nickname string `json:"nickname"`
+	}
+	if err = b.request(ctx, http.MethodGet, "/user", nil, "", &login); err != nil {
+		return
+	}
+	b.User = login.Username
+	if b.User == "" {
+		b.User = login.Nickname
+	}
+
+	// Generate PlatformName
+	pu, err := url.Parse(b.Url)
+	if err != nil {
+		return
+	}
+	host := strings.TrimPrefix(pu.Host, "api.")
+	b.PlatformName = fmt.Sprintf("%s:%s", host, b.User)
+
+	return
+}
+
+// List
+func (b *BitbucketClient) List(ctx context.Context, isFile, isSecret bool) (snippetList SnippetList, err error) {
+	snippets, err := b.listSnippets(ctx)
+	if err != nil {
+		return
+	}
+
+	// list of snippets does not have files, so get each snippet.
+	if isFile {
+		if err = b.fillSnippetFiles(ctx, snippets); err != nil {
+			return
+		}
+	}
+
+	for _, s := range snippets {
+		if !isSecret && s.IsPrivate {
+			continue
+		}
+
+		// get Description
+		title := replaceNewline(s.Title, "\\n")
+
+		visibility := BitbucketIsPublic
+		if s.IsPrivate {
+			visibility = BitbucketIsPrivate
+		}
+
+		data := SnippetListData{
+			Client:     b,
+			Platform:   b.PlatformName,
+			Id:         getBitbucketSnippetId(s),
+			Title:      title,
+			URL:        s.Links.HTML.Href,
+			Visibility: visibility.GetCode(),
+			Secret:     s.IsPrivate,
+			UpdatedAt:  s.UpdatedOn,
+		}
+
+		if isFile {
+			for _, name := range getBitbucketSnippetPaths(s) {
+				fd := data
+				fd.URL, _ = url.JoinPath(fd.URL, name)
+				fd.RawURL = s.Files[name].Links.Self.Href
+				snippetList = append(snippetList, &fd)
+			}
+		} else {
+			snippetList = append(snippetList, &data)
+		}
+	}
+
+	return
+}
+
+// Get
+func (b *BitbucketClient) Get(ctx context.Context, id string) (snippet SnippetData, err error) {
+	s, err := b.getSnippet(ctx, id)
+	if err != nil {
+		return
+	}
+
+	snippet = b.createSnippetData(s)
+
+	// get contents of files
+	for i, f := range snippet.Files {
+		contents, ferr := b.getRaw(ctx, f.RawURL)
+		if ferr != nil {
+			return snippet, ferr
+		}
+
+		snippet.Files[i].Contents = contents
+	}
+
+	return
+}
+
+// Create
+func (b *BitbucketClient) Create(ctx context.Context, data SnippetData) (snippet SnippetData, err error) {
+	// set default visiblity
+	if data.Visibility == (Visibility{}) {
+		data.Visibility = BitbucketIsPrivate
+	}
+
+	body, contentType, err := createBitbucketSnippetForm(data, nil)
+	if err != nil {
+		return
+	}
+
+	p := "/snippets"
+	if b.Workspace != "" {
+		p += "/" + url.PathEscape(b.Workspace)
+	}
+
+	var s bitbucketSnippet
+	if err = b.request(ctx, http.MethodPost, p, body, contentType, &s); err != nil {
+		return
+	}
+
+	return b.createSnippetData(&s), nil
+}
+
+// Update
+func (b *BitbucketClient) Update(ctx context.Context, id string, data SnippetData) (snippet SnippetData, err error) {
+	// renamed and deleted files are deleted after uploading new files.
+	var deletePaths []string
+	for _, f := range data.Files {
+		switch {
+		case f.Deleted:
+			deletePaths = append(deletePaths, f.Path)
+		case f.PreviousPath != "" && f.PreviousPath != f.Path:
+			deletePaths = append(deletePaths, f.PreviousPath)
+		}
+	}
+
+	body, contentType, err := createBitbucketSnippetForm(data, deletePaths)
+	if err != nil {
+		return
+	}
+
+	var s bitbucketSnippet
+	if err = b.request(ctx, http.MethodPut, "/snippets/"+id, body, contentType, &s); err != nil {
+		return
+	}
+
+	return b.createSnippetData(&s), nil
+}
+
+// Delete
+func (b *BitbucketClient) Delete(ctx context.Context, id string) (err error) {
+	return b.request(ctx, http.MethodDelete, "/snippets/"+id, nil, "", nil)
+}
+
+// ListTokenScopes
+func (b *BitbucketClient) ListTokenScopes(ctx context.Context) (scopes []string, err error) {
+	req, err := b.newRequest(ctx, http.MethodGet, "/user", nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newHTTPError(req, resp)
+	}
+
+	return splitScopes(resp.Header.Get("X-OAuth-Scopes")), nil
+}
+
+// GetPlatformName
+func (b *BitbucketClient) GetPlatformName() string {
+	return b.PlatformName
+}
+
+// GetWorkspaceList returns the slugs of workspaces the user is a member of.
+func (b *BitbucketClient) GetWorkspaceList(ctx context.Context) (workspaceList []string, err error) {
+	next := "/user/permissions/workspaces?pagelen=100"
+	for next != "" {
+		var page struct {
+			Values []struct {
+				Workspace struct {
+					Slug string `json:"slug"`
+				} `json:"workspace"`
+			} `json:"values"`
+			Next string `json:"next"`
+		}
+
+		if err = b.request(ctx, http.MethodGet, next, nil, "", &page); err != nil {
+			return []string{}, err
+		}
+
+		for _, v := range page.Values {
+			workspaceList = append(workspaceList, v.Workspace.Slug)
+		}
+
+		next = page.Next
+	}
+
+	sort.Strings(workspaceList)
+
+	return
+}
+
+// GetFilterKey
+func (b *BitbucketClient) GetFilterKey() string {
+	return b.FilterKey
+}
+
+// SetFilterKey
+func (b *BitbucketClient) SetFilterKey(key string) {
+	b.FilterKey = key
+}
+
+// VisibilityList
+func (b *BitbucketClient) VisibilityList() (visibilityList []Visibility) {
+	visibilityList = []Visibility{
+		BitbucketIsPrivate,
+		BitbucketIsPublic,
+	}
+
+	return
+}
+
+// listSnippets gets all pages of snippet list.
+func (b *BitbucketClient) listSnippets(ctx context.Context) (snippets []*bitbucketSnippet, err error) {
+	next := "/snippets?role=owner&pagelen=100"
+	if b.Workspace != "" {
+		next = "/snippets/" + url.PathEscape(b.Workspace) + "?pagelen=100"
+	}
+
+	for next != "" {
+		var page struct {
+			Values []*bitbucketSnippet `json:"values"`
+			Next   string              `json:"next"`
+		}
+
+		if err = b.request(ctx, http.MethodGet, next, nil, "", &page); err != nil {
+			return
+		}
+
+		snippets = append(snippets, page.Values...)
+		next = page.Next
+	}
+
+	return
+}
+
+// fillSnippetFiles gets the files of snippets concurrently.
+func (b *BitbucketClient) fillSnippetFiles(ctx context.Context, snippets []*bitbucketSnippet) (err error) {
+	errs := make([]error, len(snippets))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, bitbucketConcurrency)
+	for i, s := range snippets {
+		if len(s.Files) > 0 {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, s *bitbucketSnippet) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			full, ferr := b.getSnippet(ctx, getBitbucketSnippetId(s))
+			if ferr != nil {
+				errs[i] = ferr
+				return
+			}
+
+			s.Files = full.Files
+		}(i, s)
+	}
+	wg.Wait()
+
+	for _, e := range errs {
+		if e != nil {
+			return e
+		}
+	}
+
+	return
+}
+
+// getSnippet
+func (b *BitbucketClient) getSnippet(ctx context.Context, id string) (s *bitbucketSnippet, err error) {
+	s = &bitbucketSnippet{}
+	err = b.request(ctx, http.MethodGet, "/snippets/"+id, nil, "", s)
+
+	return
+}
+
+// getRaw gets the raw contents of the file.
+func (b *BitbucketClient) getRaw(ctx context.Context, u string) (contents []byte, err error) {
+	req, err := b.newRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newHTTPError(req, resp)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// request calls Bitbucket REST API. `p` is the path under Url, or the absolute url of `next` page.
+func (b *BitbucketClient) request(ctx context.Context, method, p string, body io.Reader, contentType string, out interface{}) (err error) {
+	req, err := b.newRequest(ctx, method, p, body)
+	if err != nil {
+		return
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	return doHTTPRequest(b.client, req, out)
+}
+
+// newRequest creates the authenticated request.
+func (b *BitbucketClient) newRequest(ctx context.Context, method, p string, body io.Reader) (req *http.Request, err error) {
+	u := p
+	if strings.HasPrefix(p, "/") {
+		u = b.Url + p
+	}
+
+	req, err = http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return
+	}
+
+	if b.authUser != "" {
+		req.SetBasicAuth(b.authUser, b.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+
+	return
+}
+
+// createSnippetData creates SnippetData without the contents of files.
+func (b *BitbucketClient) createSnippetData(s *bitbucketSnippet) SnippetData {
+	files := []SnippetFileData{}
+	for _, name := range getBitbucketSnippetPaths(s) {
+		files = append(files, SnippetFileData{
+			Filter: s.Links.HTML.Href + "/" + name,
+			RawURL: s.Files[name].Links.Self.Href,
+			Path:   name,
+		})
+	}
+
+	visibility := BitbucketIsPublic
+	if s.IsPrivate {
+		visibility = BitbucketIsPrivate
+	}
+
+	return SnippetData{
+		Id:         getBitbucketSnippetId(s),
+		Title:      s.Title,
+		URL:        s.Links.HTML.Href,
+		Visibility: visibility,
+		Files:      files,
+		CreatedAt:  s.CreatedOn,
+		UpdatedAt:  s.UpdatedOn,
+	}
+}
+
+// createBitbucketSnippetForm creates multipart form of snippet.
+// deletePaths are sent as `file` fields without contents, that deletes the files.
+func createBitbucketSnippetForm(data SnippetData, deletePaths []string) (body *bytes.Buffer, contentType string, err error) {
+	body = &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	if err = w.WriteField("title", data.Title); err != nil {
+		return
+	}
+
+	if data.Visibility != (Visibility{}) {
+		isPrivate := data.Visibility != BitbucketIsPublic
+		if err = w.WriteField("is_private", strconv.FormatBool(isPrivate)); err != nil {
+			return
+		}
+	}
+
+	for _, f := range data.Files {
+		if f.Deleted {
+			continue
+		}
+
+		fw, ferr := w.CreateFormFile("file", f.Path)
+		if ferr != nil {
+			return body, contentType, ferr
+		}
+
+		if _, err = fw.Write(f.Contents); err != nil {
+			return
+		}
+	}
+
+	for _, p := range deletePaths {
+		if err = w.WriteField("file", p); err != nil {
+			return
+		}
+	}
+
+	if err = w.Close(); err != nil {
+		return
+	}
+
+	return body, w.FormDataContentType(), nil
+}
+
+// getBitbucketSnippetId returns `workspace/encoded_id` from the self link of snippet.
+func getBitbucketSnippetId(s *bitbucketSnippet) string {
+	href := s.Links.Self.Href
+	if i := strings.LastIndex(href, "/snippets/"); i >= 0 {
+		return href[i+len("/snippets/"):]
+	}
+
+	return s.Id
+}
+
+// getBitbucketSnippetPaths
+func getBitbucketSnippetPaths(s *bitbucketSnippet) (paths []string) {
+	for name := range s.Files {
+		paths = append(paths, name)
+	}
+	sort.Strings(paths)
+
+	return
+}
@@ -0,0 +1,331 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeBitbucketSnippet
+type fakeBitbucketSnippet struct {
+	title     string
+	isPrivate bool
+	files     map[string][]byte
+}
+
+// fakeBitbucket is the stand-in of Bitbucket Cloud REST API used by BitbucketClient.
+// The snippets are kept in memory as `workspace/id` -> snippet. The list is paged by a snippet.
+type fakeBitbucket struct {
+	m        sync.Mutex
+	snippets map[string]*fakeBitbucketSnippet
+	next     int
+	srv      *httptest.Server
+}
+
+// newFakeBitbucket
+func newFakeBitbucket(t *testing.T) *fakeBitbucket {
+	f := &fakeBitbucket{snippets: map[string]*fakeBitbucketSnippet{}}
+	f.srv = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.srv.Close)
+
+	return f
+}
+
+// createSnippetJSON creates the snippet object of the API. The list of snippets has no files.
+func (f *fakeBitbucket) createSnippetJSON(id string, s *fakeBitbucketSnippet, withFiles bool) map[string]interface{} {
+	self := f.srv.URL + "/2.0/snippets/" + id
+	html := f.srv.URL + "/snippets/" + id
+
+	v := map[string]interface{}{
+		"id":         id[strings.Index(id, "/")+1:],
+		"title":      s.title,
+		"is_private": s.isPrivate,
+		"links": map[string]interface{}{
+			"self": map[string]string{"href": self},
+			"html": map[string]string{"href": html},
+		},
+	}
+
+	if withFiles {
+		files := map[string]interface{}{}
+		for name := range s.files {
+			files[name] = map[string]interface{}{
+				"links": map[string]interface{}{
+					"self": map[string]string{"href": self + "/files/" + name},
+					"html": map[string]string{"href": html + "#file-" + name},
+				},
+			}
+		}
+		v["files"] = files
+	}
+
+	return v
+}
+
+// writeError writes the error response of Bitbucket.
+func (f *fakeBitbucket) writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	w.Write([]byte(`{"type":"error","error":{"message":"` + message + `"}}`))
+}
+
+// updateSnippet applies the multipart form of the request to s.
+// `file` fields with contents upload the files, and `file` fields without contents delete them.
+func (f *fakeBitbucket) updateSnippet(r *http.Request, s *fakeBitbucketSnippet) error {
+	if err := r.ParseMultipartForm(1 << 20); err != nil {
+		return err
+	}
+
+	s.title = r.FormValue("title")
+	if v := r.FormValue("is_private"); v != "" {
+		s.isPrivate = v == "true"
+	}
+
+	for _, fh := range r.MultipartForm.File["file"] {
+		file, err := fh.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+
+		s.files[fh.Filename] = data
+	}
+
+	for _, name := range r.MultipartForm.Value["file"] {
+		delete(s.files, name)
+	}
+
+	return nil
+}
+
+func (f *fakeBitbucket) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		f.writeError(w, http.StatusUnauthorized, "token is invalid")
+		return
+	}
+
+	p := strings.TrimPrefix(r.URL.Path, "/2.0")
+	switch {
+	case r.Method == http.MethodGet && p == "/user":
+		writeJSON(w, map[string]string{"username": "user"})
+
+	// list, a snippet per page
+	case r.Method == http.MethodGet && (p == "/snippets" || p == "/snippets/workspace"):
+		ids := []string{}
+		for id := range f.snippets {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page = max(page, 1)
+
+		values := []interface{}{}
+		next := ""
+		if page <= len(ids) {
+			values = append(values, f.createSnippetJSON(ids[page-1], f.snippets[ids[page-1]], false))
+		}
+		if page < len(ids) {
+			next = f.srv.URL + r.URL.Path + "?page=" + strconv.Itoa(page+1)
+		}
+		writeJSON(w, map[string]interface{}{"values": values, "next": next})
+
+	case r.Method == http.MethodPost && p == "/snippets/workspace":
+		f.next++
+		id := "workspace/" + strconv.Itoa(f.next)
+		s := &fakeBitbucketSnippet{files: map[string][]byte{}}
+		if err := f.updateSnippet(r, s); err != nil {
+			f.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		f.snippets[id] = s
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, f.createSnippetJSON(id, s, true))
+
+	case strings.HasPrefix(p, "/snippets/"):
+		rest := strings.TrimPrefix(p, "/snippets/")
+		id, name, isFile := strings.Cut(strings.Replace(rest, "/files/", "\x00", 1), "\x00")
+
+		s, ok := f.snippets[id]
+		if !ok {
+			f.writeError(w, http.StatusNotFound, "snippet not found")
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodGet && isFile:
+			data, ok := s.files[name]
+			if !ok {
+				f.writeError(w, http.StatusNotFound, "file not found")
+				return
+			}
+			w.Write(data)
+
+		case r.Method == http.MethodGet:
+			writeJSON(w, f.createSnippetJSON(id, s, true))
+
+		case r.Method == http.MethodPut:
+			if err := f.updateSnippet(r, s); err != nil {
+				f.writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeJSON(w, f.createSnippetJSON(id, s, true))
+
+		case r.Method == http.MethodDelete:
+			delete(f.snippets, id)
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			f.writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+
+	default:
+		f.writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// newTestBitbucketClient
+func newTestBitbucketClient(t *testing.T, f *fakeBitbucket) *BitbucketClient {
+	b := &BitbucketClient{Workspace: "workspace"}
+	if err := b.Init(context.Background(), f.srv.URL+"/2.0/", "", "secret"); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	return b
+}
+
+func TestBitbucketClientInit(t *testing.T) {
+	f := newFakeBitbucket(t)
+	b := newTestBitbucketClient(t, f)
+
+	if want := strings.TrimPrefix(f.srv.URL, "http://") + ":user"; b.GetPlatformName() != want {
+		t.Errorf("GetPlatformName() = %s, want %s", b.GetPlatformName(), want)
+	}
+
+	// invalid token
+	err := (&BitbucketClient{}).Init(context.Background(), f.srv.URL+"/2.0", "", "invalid")
+	if !errors.Is(classifyError(err), ErrAuth) {
+		t.Errorf("Init() with invalid token error = %v, want ErrAuth", err)
+	}
+}
+
+func TestBitbucketClientSnippet(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeBitbucket(t)
+	b := newTestBitbucketClient(t, f)
+
+	// create
+	created, err := b.Create(ctx, SnippetData{
+		Title: "title",
+		Files: []SnippetFileData{
+			{Path: "a.sh", Contents: []byte("echo a\n")},
+			{Path: "b.sh", Contents: []byte("echo b\n")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if created.Id != "workspace/1" || created.Visibility != BitbucketIsPrivate || len(created.Files) != 2 {
+		t.Errorf("Create() = %+v", created)
+	}
+
+	public, err := b.Create(ctx, SnippetData{Title: "public", Visibility: BitbucketIsPublic, Files: []SnippetFileData{{Path: "c.sh"}}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	// list of all pages
+	list, err := b.List(ctx, false, true)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 || list[0].Id != created.Id || list[0].URL != created.URL || !list[0].Secret {
+		t.Fatalf("List() = %+v", list)
+	}
+
+	// private snippets are hidden without isSecret
+	if list, _ = b.List(ctx, false, false); len(list) != 1 || list[0].Id != public.Id {
+		t.Errorf("List() without secret = %+v", list)
+	}
+
+	// list files. the files are got from each snippet.
+	if list, _ = b.List(ctx, true, true); len(list) != 3 || list[1].URL != created.URL+"/b.sh" {
+		t.Errorf("List() of files = %+v", list)
+	}
+
+	// get
+	snippet, err := b.Get(ctx, created.Id)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if snippet.Title != "title" || len(snippet.Files) != 2 || string(snippet.Files[1].Contents) != "echo b\n" {
+		t.Errorf("Get() = %+v", snippet)
+	}
+
+	// update, rename and add file
+	updated, err := b.Update(ctx, created.Id, SnippetData{
+		Title:      "new title",
+		Visibility: BitbucketIsPublic,
+		Files: []SnippetFileData{
+			{Path: "c.sh", PreviousPath: "a.sh", Contents: []byte("echo c\n")},
+			{Path: "d.sh", Contents: []byte("echo d\n")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	var paths []string
+	for _, file := range updated.Files {
+		paths = append(paths, file.Path)
+	}
+	if strings.Join(paths, ",") != "b.sh,c.sh,d.sh" || updated.Title != "new title" || updated.Visibility != BitbucketIsPublic {
+		t.Errorf("Update() = %+v", updated)
+	}
+
+	if got := string(f.snippets[created.Id].files["c.sh"]); got != "echo c\n" {
+		t.Errorf("Update() contents of c.sh = %q", got)
+	}
+
+	// delete file
+	updated, err = b.Update(ctx, created.Id, SnippetData{Title: "new title", Files: []SnippetFileData{{Path: "d.sh", Deleted: true}}})
+	if err != nil {
+		t.Fatalf("Update() with deleted file error = %v", err)
+	}
+	if _, ok := f.snippets[created.Id].files["d.sh"]; ok || len(updated.Files) != 2 {
+		t.Errorf("Update() did not delete the file: %+v", updated.Files)
+	}
+
+	// delete
+	if err = b.Delete(ctx, created.Id); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	// not found
+	if _, err = b.Get(ctx, created.Id); !errors.Is(classifyError(err), ErrNotFound) {
+		t.Errorf("Get() of deleted snippet error = %v, want ErrNotFound", err)
+	}
+	if err = b.Delete(ctx, created.Id); !errors.Is(classifyError(err), ErrNotFound) {
+		t.Errorf("Delete() of deleted snippet error = %v, want ErrNotFound", err)
+	}
+}
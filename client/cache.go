@@ -0,0 +1,482 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Cache is the on-disk cache of snippet lists and contents.
+// When Client.Cache is set, each account is initialized lazily and the list is served
+// from the cache while it is within TTL. If the remote platform can not be reached, the cached data is used.
+type Cache struct {
+	// Dir is the directory to store the cache.
+	Dir string
+
+	// TTL is the duration to use the cached list without refreshing.
+	TTL time.Duration
+
+	// Refresh ignores the cached data and refreshes all of it.
+	Refresh bool
+}
+
+// cacheSinceMargin is subtracted from the last fetch time in incremental refresh, for clock skew.
+const cacheSinceMargin = time.Minute
+
+// The incremental refresh can not detect the snippets deleted remotely, so the whole list is got again
+// when cacheFullListTTLs * TTL (at least cacheFullListMinInterval) has passed since the last full list.
+const (
+	cacheFullListTTLs        = 10
+	cacheFullListMinInterval = time.Hour
+)
+
+// cacheAccount
+type cacheAccount struct {
+	PlatformName string `json:"platform_name"`
+}
+
+// cacheList
+type cacheList struct {
+	FetchedAt     time.Time       `json:"fetched_at"`
+	FullFetchedAt time.Time       `json:"full_fetched_at"`
+	Stale         bool            `json:"stale"`
+	Entries       []cacheListData `json:"entries"`
+}
+
+// cacheListData
+type cacheListData struct {
+	Platform   string    `json:"platform"`
+	Id         string    `json:"id"`
+	Title      string    `json:"title"`
+	RawURL     string    `json:"raw_url"`
+	URL        string    `json:"url"`
+	Visibility string    `json:"visibility"`
+	Secret     bool      `json:"secret"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// cacheSnippet
+type cacheSnippet struct {
+	UpdatedAt time.Time   `json:"updated_at"`
+	FetchedAt time.Time   `json:"fetched_at"`
+	Data      SnippetData `json:"data"`
+}
+
+// cachedClient is the GitClient that wraps another GitClient with Cache.
+type cachedClient struct {
+	client GitClient
+	cache  *Cache
+	dir    string
+
+	// init initializes client. it is called lazily at the first access to the remote platform.
+	init        func(ctx context.Context) error
+	once        sync.Once
+	initErr     error
+	initialized atomic.Bool
+
+	m         sync.Mutex
+	updatedAt map[string]time.Time
+}
+
+// newCachedClient
+func (c *Cache) newCachedClient(key string, gc GitClient, init func(ctx context.Context) error) *cachedClient {
+	return &cachedClient{
+		client:    gc,
+		cache:     c,
+		dir:       filepath.Join(c.Dir, key),
+		init:      init,
+		updatedAt: map[string]time.Time{},
+	}
+}
+
+// getCacheKey returns the cache directory name of the account.
+func getCacheKey(kind string, values ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(values, "\n")))
+	return kind + "_" + hex.EncodeToString(sum[:8])
+}
+
+// ensureInit
+// The ctx of the first call is used for Init.
+func (cc *cachedClient) ensureInit(ctx context.Context) error {
+	cc.once.Do(func() {
+		cc.initErr = cc.init(ctx)
+		if cc.initErr != nil {
+			return
+		}
+
+		cc.initialized.Store(true)
+		writeCacheFile(filepath.Join(cc.dir, "account.json"), cacheAccount{PlatformName: cc.client.GetPlatformName()})
+	})
+
+	return cc.initErr
+}
+
+// initClient initializes the client if it is initialized lazily.
+func initClient(ctx context.Context, gc GitClient) error {
+	if cc, ok := gc.(*cachedClient); ok {
+		return cc.ensureInit(ctx)
+	}
+
+	return nil
+}
+
+// Unwrap returns the wrapped GitClient. Call initClient before using it.
+func (cc *cachedClient) Unwrap() GitClient {
+	return cc.client
+}
+
+// GetPlatformName
+// The cached name is used before Init, and also when Init is failed (offline).
+func (cc *cachedClient) GetPlatformName() string {
+	if cc.initialized.Load() {
+		return cc.client.GetPlatformName()
+	}
+
+	var account cacheAccount
+	if err := readCacheFile(filepath.Join(cc.dir, "account.json"), &account); err == nil && account.PlatformName != "" {
+		return account.PlatformName
+	}
+
+	return cc.client.GetPlatformName()
+}
+
+// GetFilterKey
+func (cc *cachedClient) GetFilterKey() string {
+	return cc.client.GetFilterKey()
+}
+
+// SetFilterKey
+func (cc *cachedClient) SetFilterKey(key string) {
+	cc.client.SetFilterKey(key)
+}
+
+// List
+func (cc *cachedClient) List(ctx context.Context, isFile, isSecret bool) (snippetList SnippetList, err error) {
+	path := cc.listPath(isFile)
+
+	var cl cacheList
+	hasCache := readCacheFile(path, &cl) == nil
+
+	// use cache within TTL
+	if hasCache && !cc.cache.Refresh && !cl.Stale && time.Since(cl.FetchedAt) < cc.cache.TTL {
+		return cc.createSnippetList(cl.Entries, isSecret), nil
+	}
+
+	entries, err := cc.fetchList(ctx, isFile, hasCache, cl)
+	if err != nil {
+		// canceled by user. (timeout uses the cache as offline)
+		if !hasCache || errors.Is(ctx.Err(), context.Canceled) {
+			return
+		}
+
+		// offline
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s. use cached list.\n", cc.GetPlatformName(), err)
+		return cc.createSnippetList(cl.Entries, isSecret), nil
+	}
+
+	return cc.createSnippetList(entries, isSecret), nil
+}
+
+// fetchList gets the list from the remote platform and saves it to the cache.
+// If the client can list only the updated snippets, the cached list is refreshed incrementally.
+func (cc *cachedClient) fetchList(ctx context.Context, isFile, hasCache bool, cl cacheList) (entries []cacheListData, err error) {
+	if err = cc.ensureInit(ctx); err != nil {
+		return
+	}
+
+	fetchedAt := time.Now()
+	fullFetchedAt := fetchedAt
+
+	var list SnippetList
+	sl, isSinceLister := cc.client.(SnippetSinceLister)
+	if hasCache && !cc.cache.Refresh && isSinceLister && time.Since(cl.FullFetchedAt) < cc.fullListInterval() {
+		fullFetchedAt = cl.FullFetchedAt
+
+		list, err = sl.ListSince(ctx, isFile, true, cl.FetchedAt.Add(-cacheSinceMargin))
+		if err != nil {
+			return
+		}
+
+		// replace updated snippets
+		updated := map[string]bool{}
+		for _, l := range list {
+			updated[l.Id] = true
+		}
+
+		for _, e := range cl.Entries {
+			if !updated[e.Id] {
+				entries = append(entries, e)
+			}
+		}
+	} else {
+		list, err = cc.client.List(ctx, isFile, true)
+		if err != nil {
+			return
+		}
+	}
+
+	for _, l := range list {
+		entries = append(entries, cacheListData{
+			Platform:   l.Platform,
+			Id:         l.Id,
+			Title:      l.Title,
+			RawURL:     l.RawURL,
+			URL:        l.URL,
+			Visibility: l.Visibility,
+			Secret:     l.Secret,
+			UpdatedAt:  l.UpdatedAt,
+		})
+	}
+
+	if isSinceLister {
+		sort.SliceStable(entries, func(i, j int) bool {
+			return entries[i].URL < entries[j].URL
+		})
+	}
+
+	err = writeCacheFile(cc.listPath(isFile), cacheList{FetchedAt: fetchedAt, FullFetchedAt: fullFetchedAt, Entries: entries})
+
+	return
+}
+
+// fullListInterval
+func (cc *cachedClient) fullListInterval() time.Duration {
+	return max(cacheFullListTTLs*cc.cache.TTL, cacheFullListMinInterval)
+}
+
+// createSnippetList
+func (cc *cachedClient) createSnippetList(entries []cacheListData, isSecret bool) (snippetList SnippetList) {
+	cc.m.Lock()
+	defer cc.m.Unlock()
+
+	for _, e := range entries {
+		cc.updatedAt[e.Id] = e.UpdatedAt
+
+		if !isSecret && e.Secret {
+			continue
+		}
+
+		snippetList = append(snippetList, &SnippetListData{
+			Client:     cc,
+			Platform:   e.Platform,
+			Id:         e.Id,
+			Title:      e.Title,
+			RawURL:     e.RawURL,
+			URL:        e.URL,
+			Visibility: e.Visibility,
+			Secret:     e.Secret,
+			UpdatedAt:  e.UpdatedAt,
+		})
+	}
+
+	return
+}
+
+// Get
+func (cc *cachedClient) Get(ctx context.Context, id string) (data SnippetData, err error) {
+	path := cc.snippetPath(id)
+
+	var cs cacheSnippet
+	hasCache := readCacheFile(path, &cs) == nil
+
+	cc.m.Lock()
+	updatedAt := cc.updatedAt[id]
+	cc.m.Unlock()
+
+	// use cache if the snippet is not updated
+	if hasCache && !cc.cache.Refresh && !updatedAt.IsZero() && cs.UpdatedAt.Equal(updatedAt) {
+		return cs.Data, nil
+	}
+
+	if err = cc.ensureInit(ctx); err == nil {
+		data, err = cc.client.Get(ctx, id)
+	}
+
+	if err != nil {
+		// canceled by user. (timeout uses the cache as offline)
+		if !hasCache || errors.Is(ctx.Err(), context.Canceled) {
+			return
+		}
+
+		// offline
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s. use cached snippet.\n", cc.GetPlatformName(), err)
+		return cs.Data, nil
+	}
+
+	writeCacheFile(path, cacheSnippet{UpdatedAt: updatedAt, FetchedAt: time.Now(), Data: data})
+
+	return
+}
+
+// Create
+func (cc *cachedClient) Create(ctx context.Context, data SnippetData) (snippet SnippetData, err error) {
+	if err = cc.ensureInit(ctx); err != nil {
+		return
+	}
+
+	snippet, err = cc.client.Create(ctx, data)
+	if err == nil {
+		cc.invalidate("", false)
+	}
+
+	return
+}
+
+// Update
+func (cc *cachedClient) Update(ctx context.Context, id string, data SnippetData) (snippet SnippetData, err error) {
+	if err = cc.ensureInit(ctx); err != nil {
+		return
+	}
+
+	snippet, err = cc.client.Update(ctx, id, data)
+	if err == nil {
+		cc.invalidate(id, false)
+	}
+
+	return
+}
+
+// Delete
+func (cc *cachedClient) Delete(ctx context.Context, id string) (err error) {
+	if err = cc.ensureInit(ctx); err != nil {
+		return
+	}
+
+	err = cc.client.Delete(ctx, id)
+	if err == nil {
+		cc.invalidate(id, true)
+	}
+
+	return
+}
+
+// VisibilityList
+func (cc *cachedClient) VisibilityList() (visibilityList []Visibility) {
+	return cc.client.VisibilityList()
+}
+
+// invalidate marks the cached lists as stale and removes the cached snippet.
+// Deleted snippet is also removed from the lists, because the incremental refresh can not detect it.
+func (cc *cachedClient) invalidate(id string, isDeleted bool) {
+	if id != "" {
+		os.Remove(cc.snippetPath(id))
+	}
+
+	for _, isFile := range []bool{false, true} {
+		var cl cacheList
+		if err := readCacheFile(cc.listPath(isFile), &cl); err != nil {
+			continue
+		}
+
+		if isDeleted {
+			entries := []cacheListData{}
+			for _, e := range cl.Entries {
+				if e.Id != id {
+					entries = append(entries, e)
+				}
+			}
+			cl.Entries = entries
+		}
+
+		cl.Stale = true
+		writeCacheFile(cc.listPath(isFile), cl)
+	}
+}
+
+// listPath
+func (cc *cachedClient) listPath(isFile bool) string {
+	if isFile {
+		return filepath.Join(cc.dir, "list_file.json")
+	}
+
+	return filepath.Join(cc.dir, "list.json")
+}
+
+// snippetPath
+func (cc *cachedClient) snippetPath(id string) string {
+	return filepath.Join(cc.dir, "snippets", url.PathEscape(id)+".json")
+}
+
+// deriveClient returns the client to use gc, which is derived from the initialized client parent.
+// ex) project snippet client of gitlab. If parent is cached, gc shares its cache, so that Create invalidates the list of parent.
+func deriveClient(parent, gc GitClient) GitClient {
+	cc, ok := parent.(*cachedClient)
+	if !ok {
+		return gc
+	}
+
+	d := &cachedClient{
+		client:    gc,
+		cache:     cc.cache,
+		dir:       cc.dir,
+		init:      func(ctx context.Context) error { return nil },
+		updatedAt: map[string]time.Time{},
+	}
+	d.once.Do(func() {})
+	d.initialized.Store(true)
+
+	return d
+}
+
+// unwrapClient returns the GitClient wrapped by the cache.
+func unwrapClient(gc GitClient) GitClient {
+	if w, ok := gc.(interface{ Unwrap() GitClient }); ok {
+		return w.Unwrap()
+	}
+
+	return gc
+}
+
+// readCacheFile
+func readCacheFile(path string, v interface{}) (err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// writeCacheFile writes v as json. It writes to a temporary file and renames it, so that readers never see a partial file.
+func writeCacheFile(path string, v interface{}) (err error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp_*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return
+	}
+
+	if err = tmp.Close(); err != nil {
+		return
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
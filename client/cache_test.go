@@ -0,0 +1,138 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// fakeSinceClient is the GitClient that lists ids. ListSince returns only the ids in updated.
+type fakeSinceClient struct {
+	ids     []string
+	updated []string
+	full    int
+	filter  string
+}
+
+func (f *fakeSinceClient) GetPlatformName() string      { return "fake" }
+func (f *fakeSinceClient) GetFilterKey() string         { return f.filter }
+func (f *fakeSinceClient) SetFilterKey(key string)      { f.filter = key }
+func (f *fakeSinceClient) VisibilityList() []Visibility { return nil }
+
+func (f *fakeSinceClient) List(ctx context.Context, isFile, isSecret bool) (SnippetList, error) {
+	f.full++
+	return f.createList(f.ids), nil
+}
+
+func (f *fakeSinceClient) ListSince(ctx context.Context, isFile, isSecret bool, since time.Time) (SnippetList, error) {
+	return f.createList(f.updated), nil
+}
+
+func (f *fakeSinceClient) Get(ctx context.Context, id string) (SnippetData, error) {
+	return SnippetData{Id: id}, nil
+}
+
+func (f *fakeSinceClient) Create(ctx context.Context, data SnippetData) (SnippetData, error) {
+	return data, nil
+}
+
+func (f *fakeSinceClient) Update(ctx context.Context, id string, data SnippetData) (SnippetData, error) {
+	return data, nil
+}
+
+func (f *fakeSinceClient) Delete(ctx context.Context, id string) error {
+	return nil
+}
+
+func (f *fakeSinceClient) createList(ids []string) (list SnippetList) {
+	for _, id := range ids {
+		list = append(list, &SnippetListData{Client: f, Platform: "fake", Id: id, URL: "fake://" + id})
+	}
+
+	return
+}
+
+func getSnippetListIds(list SnippetList) (ids []string) {
+	for _, l := range list {
+		ids = append(ids, l.Id)
+	}
+	sort.Strings(ids)
+
+	return
+}
+
+func TestCachedClientFullList(t *testing.T) {
+	ctx := context.Background()
+	fc := &fakeSinceClient{ids: []string{"a", "b"}}
+	cache := &Cache{Dir: t.TempDir(), TTL: 0}
+	cc := cache.newCachedClient("fake", fc, func(ctx context.Context) error { return nil })
+
+	if _, err := cc.List(ctx, false, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// b is deleted remotely. the incremental refresh does not notice it.
+	fc.ids = []string{"a"}
+	list, err := cc.List(ctx, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := getSnippetListIds(list); len(got) != 2 || fc.full != 1 {
+		t.Fatalf("incremental refresh: ids = %v, full list count = %d", got, fc.full)
+	}
+
+	// after the interval, the whole list is got again
+	var cl cacheList
+	if err = readCacheFile(cc.listPath(false), &cl); err != nil {
+		t.Fatal(err)
+	}
+	cl.FullFetchedAt = time.Now().Add(-2 * cacheFullListMinInterval)
+	if err = writeCacheFile(cc.listPath(false), cl); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err = cc.List(ctx, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := getSnippetListIds(list); len(got) != 1 || got[0] != "a" || fc.full != 2 {
+		t.Fatalf("full list: ids = %v, full list count = %d", got, fc.full)
+	}
+}
+
+func TestDeriveClient(t *testing.T) {
+	ctx := context.Background()
+	fc := &fakeSinceClient{ids: []string{"a"}}
+	cache := &Cache{Dir: t.TempDir(), TTL: time.Hour}
+	cc := cache.newCachedClient("fake", fc, func(ctx context.Context) error { return nil })
+
+	if _, err := cc.List(ctx, false, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// create on the derived client (ex: gitlab project snippet) invalidates the list of parent
+	pc := *fc
+	dc := deriveClient(cc, &pc)
+	if _, err := dc.Create(ctx, SnippetData{Id: "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var cl cacheList
+	if err := readCacheFile(filepath.Join(cc.dir, "list.json"), &cl); err != nil {
+		t.Fatal(err)
+	}
+	if !cl.Stale {
+		t.Error("list of parent is not stale after Create on derived client")
+	}
+
+	// not cached
+	if got := deriveClient(fc, &pc); got != GitClient(&pc) {
+		t.Errorf("deriveClient() of not cached client = %v, want %v", got, &pc)
+	}
+}
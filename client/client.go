@@ -5,49 +5,195 @@
 package client
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/blacknon/snipt/config"
-	"github.com/google/go-github/github"
-	"github.com/xanzy/go-gitlab"
+)
+
+var (
+	// ErrCommentNotSupported is returned when the platform of the snippet can not handle comments.
+	ErrCommentNotSupported = errors.New("comment is not supported on this snippet")
+
+	// ErrHistoryNotSupported is returned when the platform of the snippet can not get the revisions.
+	ErrHistoryNotSupported = errors.New("history is not supported on this snippet")
 )
 
 // Client
 type Client struct {
+	// Cache is used when it is set before Init.
+	Cache *Cache
+
+	// Strict initializes all accounts in Init, and Init returns the error if any account fails.
+	Strict bool
+
+	// Timeout is the timeout of each method accessing the remote platforms. If zero, there is no timeout.
+	Timeout time.Duration
+
+	// Verbose is the writer of verbose log (requests, retries and rate limit quota). If nil, it is not logged.
+	Verbose io.Writer
+
+	accounts        []*Account
 	lists           []GitClient
 	filterListsData SnippetList
 }
 
-// Init
-func (c *Client) Init(conf config.Config) {
+// Init initializes the clients of all accounts in config.
+// The accounts failed to initialize are skipped, and their errors are returned joined as *AccountError.
+// The accounts with the cache are initialized lazily, so their errors are returned from each method.
+func (c *Client) Init(ctx context.Context, conf config.Config) (err error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	var errs []error
+
 	// Gist.Init
-	for _, gistConf := range conf.Gist {
-		g := GistClient{}
-		g.Init(gistConf.AccessToken)
+	for i, gistConf := range conf.Gist {
+		u, uploadURL, token := gistConf.Url, gistConf.UploadUrl, gistConf.AccessToken
+
+		// github.com account keeps the cache key without url.
+		cacheKey := getCacheKey("gist", token)
+		target := "github.com"
+		if u != "" {
+			cacheKey = getCacheKey("gist", u, token)
+			target = u
+		}
 
-		c.lists = append(c.lists, &g)
+		name := getAccountName("Gist", i, target)
+		g := &GistClient{
+			httpOption: c.newHTTPOption(name, gistConf.HTTPConfig),
+		}
+		errs = append(errs, c.addClient(ctx, name, cacheKey, g, func(ctx context.Context) error {
+			return g.Init(ctx, u, uploadURL, token)
+		}))
 	}
 
 	// Gitlab.Init
-	for _, gitlabConf := range conf.GitLab {
-		g := GitlabClient{
-			proxy:     gitlabConf.Proxy,
-			proxyUser: gitlabConf.ProxyUser,
-			proxyPass: gitlabConf.ProxyPass,
+	for i, gitlabConf := range conf.GitLab {
+		gitlabConf.SetDefault()
+		u, token := gitlabConf.Url, gitlabConf.AccessToken
+
+		name := getAccountName("GitLab", i, u)
+		g := &GitlabClient{
+			httpOption: c.newHTTPOption(name, gitlabConf.HTTPConfig),
+		}
+		errs = append(errs, c.addClient(ctx, name, getCacheKey("gitlab", u, token), g, func(ctx context.Context) error {
+			return g.Init(ctx, u, token)
+		}))
+	}
+
+	// Gitea.Init
+	for i, giteaConf := range conf.Gitea {
+		giteaConf.SetDefault()
+		u, token, repo := giteaConf.Url, giteaConf.AccessToken, giteaConf.Repo
+
+		name := getAccountName("Gitea", i, u+" "+repo)
+		g := &GiteaClient{
+			httpOption: c.newHTTPOption(name, giteaConf.HTTPConfig),
+		}
+		errs = append(errs, c.addClient(ctx, name, getCacheKey("gitea", u, token, repo), g, func(ctx context.Context) error {
+			return g.Init(ctx, u, token, repo)
+		}))
+	}
+
+	// Bitbucket.Init
+	for i, bitbucketConf := range conf.Bitbucket {
+		bitbucketConf.SetDefault()
+		u, user, token := bitbucketConf.Url, bitbucketConf.User, bitbucketConf.AccessToken
+
+		name := getAccountName("Bitbucket", i, u)
+		b := &BitbucketClient{
+			httpOption: c.newHTTPOption(name, bitbucketConf.HTTPConfig),
+		}
+		errs = append(errs, c.addClient(ctx, name, getCacheKey("bitbucket", u, user, token), b, func(ctx context.Context) error {
+			return b.Init(ctx, u, user, token)
+		}))
+	}
+
+	// Local.Init
+	// local snippets are read directly, so they are not cached.
+	for i, localConf := range conf.Local {
+		localConf.SetDefault()
+		l := &LocalClient{}
+		p, git := localConf.Path, localConf.Git
+		errs = append(errs, c.addClient(ctx, getAccountName("Local", i, p), "", l, func(ctx context.Context) error {
+			return l.Init(ctx, p, git)
+		}))
+	}
+
+	return errors.Join(errs...)
+}
+
+// newHTTPOption
+func (c *Client) newHTTPOption(account string, conf config.HTTPConfig) httpOption {
+	return httpOption{
+		HTTPConfig: conf,
+		account:    account,
+		verbose:    c.Verbose,
+	}
+}
+
+// Accounts returns the accounts in config.
+func (c *Client) Accounts() []*Account {
+	return c.accounts
+}
+
+// addClient adds GitClient of the account.
+// If the cache is enabled and cacheKey is set, the client is wrapped with the cache and initialized lazily.
+// Otherwise, the client is initialized here, and it is not added if Init fails.
+func (c *Client) addClient(ctx context.Context, name, cacheKey string, gc GitClient, init func(ctx context.Context) error) (err error) {
+	account := &Account{Name: name, Client: gc}
+	c.accounts = append(c.accounts, account)
+
+	accountInit := func(ctx context.Context) error {
+		if ierr := init(ctx); ierr != nil {
+			account.Err = &AccountError{Account: name, Err: classifyError(ierr)}
+			return account.Err
+		}
+
+		return nil
+	}
+
+	if c.Cache == nil || cacheKey == "" || c.Strict {
+		if err = accountInit(ctx); err != nil {
+			return
 		}
-		g.Init(gitlabConf.Url, gitlabConf.AccessToken)
 
-		c.lists = append(c.lists, &g)
+		if c.Cache != nil && cacheKey != "" {
+			// already initialized
+			gc = c.Cache.newCachedClient(cacheKey, gc, func(context.Context) error { return nil })
+		}
+
+		c.lists = append(c.lists, gc)
+		return
 	}
+
+	c.lists = append(c.lists, c.Cache.newCachedClient(cacheKey, gc, accountInit))
+
+	return
 }
 
-// List
-func (c *Client) List(isFile, isSecret bool) SnippetList {
-	var snippetList SnippetList
-	var wg sync.WaitGroup                                 // goroutineの完了を待機するためのWaitGroup
-	resultChannel := make(chan SnippetList, len(c.lists)) // 処理結果を収集するチャネル
+// List lists the snippets of all platforms concurrently.
+// If some platforms fail, the snippets of the other platforms are returned with ListError keyed by platform name.
+func (c *Client) List(ctx context.Context, isFile, isSecret bool) (snippetList SnippetList, err error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	// listResult
+	type listResult struct {
+		platform string
+		list     SnippetList
+		err      error
+	}
+
+	var wg sync.WaitGroup                                // goroutineの完了を待機するためのWaitGroup
+	resultChannel := make(chan listResult, len(c.lists)) // 処理結果を収集するチャネル
 
 	// 各クライアントに対してgoroutineを起動
 	for _, gc := range c.lists {
@@ -55,14 +201,17 @@ func (c *Client) List(isFile, isSecret bool) SnippetList {
 		go func(gc GitClient) {
 			defer wg.Done()
 			// クライアントのListメソッドを実行
-			list, err := gc.List(isFile, isSecret)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %s\n", err)
-				return
+			list, err := gc.List(ctx, isFile, isSecret)
+			platform := c.getPlatformKey(gc)
+
+			// the account name is already the key
+			var accountErr *AccountError
+			if errors.As(err, &accountErr) && accountErr.Account == platform {
+				err = accountErr.Err
 			}
 
 			// 処理結果をチャネルに送信
-			resultChannel <- list
+			resultChannel <- listResult{platform: platform, list: list, err: classifyError(err)}
 		}(gc)
 	}
 
@@ -73,96 +222,289 @@ func (c *Client) List(isFile, isSecret bool) SnippetList {
 	}()
 
 	// チャネルから受け取ったリストをまとめる
-	for list := range resultChannel {
-		snippetList = append(snippetList, list...)
+	listErr := ListError{}
+	for result := range resultChannel {
+		if result.err != nil {
+			listErr[result.platform] = result.err
+			continue
+		}
+
+		snippetList = append(snippetList, result.list...)
 	}
 
 	// filterListsDataに結果を保存
 	c.filterListsData = snippetList
 
-	return snippetList
+	if len(listErr) > 0 {
+		err = listErr
+	}
+
+	return
+}
+
+// getPlatformKey returns the platform name of gc. If it is unknown (not initialized), the account name is returned.
+func (c *Client) getPlatformKey(gc GitClient) string {
+	if name := gc.GetPlatformName(); name != "" {
+		return name
+	}
+
+	for _, a := range c.accounts {
+		if a.Client == unwrapClient(gc) {
+			return a.Name
+		}
+	}
+
+	return ""
 }
 
 // Get
-func (c *Client) Get(url string) (snippet SnippetData, err error) {
+func (c *Client) Get(ctx context.Context, url string) (snippet SnippetData, err error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	cl := c.filterListsData.Where(func(s *SnippetListData) bool {
 		return s.URL == url
 	})
 
 	if len(cl) == 0 {
+		err = fmt.Errorf("snippet %w: %s", ErrNotFound, url)
 		return
 	}
 
 	// get SnippetListData
 	sld := cl[0]
 
-	snippet, err = sld.Client.Get(sld.Id)
+	snippet, err = sld.Client.Get(ctx, sld.Id)
 
-	return
+	return snippet, classifyError(err)
 }
 
-// Create
-func (c *Client) Create(platform string, data SnippetData) (url []string, err error) {
+// Create creates the snippet on platform, and returns the created snippets.
+func (c *Client) Create(ctx context.Context, platform string, data SnippetData) (snippets []SnippetData, err error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	for _, d := range c.filterListsData {
 		if d.Client.GetFilterKey() == platform {
-			snippet, err := d.Client.Create(data)
+			snippet, err := d.Client.Create(ctx, data)
 			if err != nil {
-				return url, err
+				return snippets, classifyError(err)
 			}
 
-			switch s := snippet.(type) {
-			case *github.Gist:
-				url = append(url, s.GetHTMLURL())
-			case *gitlab.Snippet:
-				url = append(url, s.WebURL)
-			}
+			snippets = append(snippets, snippet)
 		}
 	}
 
 	return
 }
 
-// Update
-func (c *Client) Update(url string, data SnippetData) (urlList []string, err error) {
+// Update updates the snippet of url, and returns the updated snippets.
+func (c *Client) Update(ctx context.Context, url string, data SnippetData) (snippets []SnippetData, err error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	cl := c.filterListsData.Where(func(s *SnippetListData) bool {
 		return s.URL == url
 	})
 
 	for _, d := range cl {
-		snippet, err := d.Client.Update(d.Id, data)
+		snippet, err := d.Client.Update(ctx, d.Id, data)
 		if err != nil {
-			return urlList, err
+			return snippets, classifyError(err)
 		}
 
-		switch s := snippet.(type) {
-		case *github.Gist:
-			urlList = append(urlList, s.GetHTMLURL())
-		case *gitlab.Snippet:
-			urlList = append(urlList, s.WebURL)
-		}
+		snippets = append(snippets, snippet)
 	}
 
 	return
 }
 
 // Delete
-func (c *Client) Delete(url string) (err error) {
+func (c *Client) Delete(ctx context.Context, url string) (err error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	data := c.filterListsData.Where(func(s *SnippetListData) bool {
 		return s.URL == url
 	})
 
 	for _, d := range data {
-		err := d.Client.Delete(d.Id)
+		err := d.Client.Delete(ctx, d.Id)
 		if err != nil {
-			return err
+			return classifyError(err)
 		}
 	}
 
 	return
 }
 
+// ListComments
+func (c *Client) ListComments(ctx context.Context, url string) (comments []SnippetComment, err error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	sld, commenter, err := c.getCommenter(ctx, url)
+	if err != nil {
+		return
+	}
+
+	comments, err = commenter.ListComments(ctx, sld.Id)
+
+	return comments, classifyError(err)
+}
+
+// AddComment
+func (c *Client) AddComment(ctx context.Context, url, body string) (comment SnippetComment, err error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	sld, commenter, err := c.getCommenter(ctx, url)
+	if err != nil {
+		return
+	}
+
+	comment, err = commenter.AddComment(ctx, sld.Id, body)
+
+	return comment, classifyError(err)
+}
+
+// EditComment
+func (c *Client) EditComment(ctx context.Context, url, commentId, body string) (comment SnippetComment, err error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	sld, commenter, err := c.getCommenter(ctx, url)
+	if err != nil {
+		return
+	}
+
+	comment, err = commenter.EditComment(ctx, sld.Id, commentId, body)
+
+	return comment, classifyError(err)
+}
+
+// DeleteComment
+func (c *Client) DeleteComment(ctx context.Context, url, commentId string) (err error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	sld, commenter, err := c.getCommenter(ctx, url)
+	if err != nil {
+		return
+	}
+
+	return classifyError(commenter.DeleteComment(ctx, sld.Id, commentId))
+}
+
+// getCommenter
+func (c *Client) getCommenter(ctx context.Context, url string) (sld *SnippetListData, commenter SnippetCommenter, err error) {
+	cl := c.filterListsData.Where(func(s *SnippetListData) bool {
+		return s.URL == url
+	})
+
+	if len(cl) == 0 {
+		err = fmt.Errorf("snippet %w: %s", ErrNotFound, url)
+		return
+	}
+
+	// get SnippetListData
+	sld = cl[0]
+
+	if err = initClient(ctx, sld.Client); err != nil {
+		return
+	}
+
+	commenter, ok := unwrapClient(sld.Client).(SnippetCommenter)
+	if !ok {
+		err = ErrCommentNotSupported
+		return
+	}
+
+	return
+}
+
+// ListRevisions returns the revisions of the snippet of url, newest first.
+func (c *Client) ListRevisions(ctx context.Context, url string) (revisions []SnippetRevision, err error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	sld, historian, err := c.getHistorian(ctx, url)
+	if err != nil {
+		return
+	}
+
+	revisions, err = historian.ListRevisions(ctx, sld.Id)
+
+	return revisions, classifyError(err)
+}
+
+// GetRevision returns the snippet of url at the revision.
+// revision can be the prefix of the revision. ex) the first 8 characters of the commit hash
+func (c *Client) GetRevision(ctx context.Context, url, revision string) (snippet SnippetData, err error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	sld, historian, err := c.getHistorian(ctx, url)
+	if err != nil {
+		return
+	}
+
+	snippet, err = historian.GetRevision(ctx, sld.Id, revision)
+
+	return snippet, classifyError(err)
+}
+
+// resolveRevision returns the revision in revisions that starts with prefix.
+func resolveRevision(revisions []SnippetRevision, prefix string) (revision string, err error) {
+	var matched []string
+	for _, r := range revisions {
+		if strings.HasPrefix(r.Revision, prefix) {
+			matched = append(matched, r.Revision)
+		}
+	}
+
+	switch {
+	case prefix == "" || len(matched) == 0:
+		return "", fmt.Errorf("revision %w: %s", ErrNotFound, prefix)
+	case len(matched) > 1:
+		return "", fmt.Errorf("ambiguous revision: %s", prefix)
+	}
+
+	return matched[0], nil
+}
+
+// getHistorian
+func (c *Client) getHistorian(ctx context.Context, url string) (sld *SnippetListData, historian SnippetHistorian, err error) {
+	cl := c.filterListsData.Where(func(s *SnippetListData) bool {
+		return s.URL == url
+	})
+
+	if len(cl) == 0 {
+		err = fmt.Errorf("snippet %w: %s", ErrNotFound, url)
+		return
+	}
+
+	// get SnippetListData
+	sld = cl[0]
+
+	if err = initClient(ctx, sld.Client); err != nil {
+		return
+	}
+
+	historian, ok := unwrapClient(sld.Client).(SnippetHistorian)
+	if !ok {
+		err = ErrHistoryNotSupported
+		return
+	}
+
+	return
+}
+
 // PlatformList
-func (c *Client) PlatformList(enableProject bool) ([]string, error) {
+func (c *Client) PlatformList(ctx context.Context, enableProject bool) ([]string, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	var platformList []string
 	var err error
 	var wg sync.WaitGroup // 同期用のWaitGroupを用意
@@ -183,6 +525,12 @@ func (c *Client) PlatformList(enableProject bool) ([]string, error) {
 		go func(gc GitClient) {
 			defer wg.Done()
 
+			// skip the account failed to initialize
+			if err := initClient(ctx, gc); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s. skipped.\n", err)
+				return
+			}
+
 			platformName := gc.GetPlatformName()
 			gc.SetFilterKey(platformName)
 
@@ -192,9 +540,9 @@ func (c *Client) PlatformList(enableProject bool) ([]string, error) {
 			}
 
 			// Get gitlab project list
-			glsnippet, ok := gc.(*GitlabClient)
+			glsnippet, ok := unwrapClient(gc).(*GitlabClient)
 			if enableProject && ok {
-				projects, err := glsnippet.GetProjectList()
+				projects, err := glsnippet.GetProjectList(ctx)
 				if err != nil {
 					resultChannel <- struct {
 						platform string
@@ -207,8 +555,13 @@ func (c *Client) PlatformList(enableProject bool) ([]string, error) {
 				for _, p := range projects {
 					pn := fmt.Sprintf("%s /%s", platformName, p.PathWithNamespace)
 
+					// project snippet client shares the connection of the user client.
+					pc := *glsnippet
+					pc.Project = p
+					pc.SetFilterKey(pn)
+
 					pd := &SnippetListData{
-						Client:   &GitlabClient{Project: p /* 他のフィールドを設定 */},
+						Client:   deriveClient(gc, &pc),
 						Platform: pn,
 					}
 					// 結果をチャネルに送信
@@ -218,6 +571,37 @@ func (c *Client) PlatformList(enableProject bool) ([]string, error) {
 						err      error
 					}{platform: pn, data: pd, err: nil}
 				}
+			} else if bbsnippet, isBitbucket := unwrapClient(gc).(*BitbucketClient); enableProject && isBitbucket {
+				// Get bitbucket workspace list
+				workspaces, err := bbsnippet.GetWorkspaceList(ctx)
+				if err != nil {
+					resultChannel <- struct {
+						platform string
+						data     *SnippetListData
+						err      error
+					}{platform: platformName, data: nil, err: err}
+					return
+				}
+
+				for _, w := range workspaces {
+					pn := fmt.Sprintf("%s /%s", platformName, w)
+
+					// workspace snippet client shares the connection of the user client.
+					wc := *bbsnippet
+					wc.Workspace = w
+					wc.SetFilterKey(pn)
+
+					wd := &SnippetListData{
+						Client:   deriveClient(gc, &wc),
+						Platform: pn,
+					}
+					// 結果をチャネルに送信
+					resultChannel <- struct {
+						platform string
+						data     *SnippetListData
+						err      error
+					}{platform: pn, data: wd, err: nil}
+				}
 			} else {
 				// 結果をチャネルに送信
 				resultChannel <- struct {
@@ -278,3 +662,12 @@ func (c *Client) VisibilityListFromURL(url string) (visibilityList []Visibility,
 
 	return
 }
+
+// withTimeout returns the context with Timeout.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, c.Timeout)
+}
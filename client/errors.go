@@ -0,0 +1,166 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/github"
+	"github.com/xanzy/go-gitlab"
+)
+
+// The kinds of errors from the remote platforms. Use errors.Is to check them.
+// The original error (ex: *HTTPError, *github.ErrorResponse) is still available with errors.As.
+var (
+	// ErrAuth is the authentication or authorization error. (401, 403)
+	ErrAuth = errors.New("authentication failed")
+
+	// ErrNotFound is returned when the snippet or the resource is not found. (404)
+	ErrNotFound = errors.New("not found")
+
+	// ErrRateLimited is returned when the rate limit is exceeded even after retries. (429)
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrNetwork is returned when the remote platform can not be reached, or does not respond in time.
+	ErrNetwork = errors.New("network error")
+)
+
+// ListError is the error of Client.List, keyed by platform name.
+// The snippets of the platforms without error are returned with it.
+type ListError map[string]error
+
+// Error
+func (e ListError) Error() string {
+	var lines []string
+	for _, platform := range e.platforms() {
+		lines = append(lines, platform+": "+e[platform].Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap
+func (e ListError) Unwrap() (errs []error) {
+	for _, platform := range e.platforms() {
+		errs = append(errs, e[platform])
+	}
+
+	return
+}
+
+// platforms returns the sorted platform names.
+func (e ListError) platforms() (platforms []string) {
+	for platform := range e {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	return
+}
+
+// kindError is the error wrapping err with its kind.
+// The message is not changed.
+type kindError struct {
+	kind error
+	err  error
+}
+
+// Error
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// classifyError wraps err with ErrAuth, ErrNotFound, ErrRateLimited or ErrNetwork.
+// If the kind of err is unknown, err is returned as it is.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	kind := getErrorKind(err)
+	if kind == nil || errors.Is(err, kind) {
+		return err
+	}
+
+	return &kindError{kind: kind, err: err}
+}
+
+// getErrorKind
+func getErrorKind(err error) error {
+	// canceled by user
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+
+	// local snippet
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+
+	// go-github checks the rate limit headers of 403
+	var rateLimitErr *github.RateLimitError
+	var abuseRateLimitErr *github.AbuseRateLimitError
+	if errors.As(err, &rateLimitErr) || errors.As(err, &abuseRateLimitErr) {
+		return ErrRateLimited
+	}
+
+	if statusCode := getErrorStatusCode(err); statusCode != 0 {
+		switch statusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return ErrAuth
+		case http.StatusNotFound:
+			return ErrNotFound
+		case http.StatusTooManyRequests:
+			return ErrRateLimited
+		}
+
+		return nil
+	}
+
+	var netErr net.Error
+	var urlErr *url.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) || errors.As(err, &urlErr) {
+		return ErrNetwork
+	}
+
+	return nil
+}
+
+// getErrorStatusCode returns the HTTP status code of the error response. If err is not an error response, it returns 0.
+func getErrorStatusCode(err error) int {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+
+	var githubErr *github.ErrorResponse
+	if errors.As(err, &githubErr) && githubErr.Response != nil {
+		return githubErr.Response.StatusCode
+	}
+
+	var twoFactorErr *github.TwoFactorAuthError
+	if errors.As(err, &twoFactorErr) {
+		return http.StatusUnauthorized
+	}
+
+	var gitlabErr *gitlab.ErrorResponse
+	if errors.As(err, &gitlabErr) && gitlabErr.Response != nil {
+		return gitlabErr.Response.StatusCode
+	}
+
+	return 0
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/google/go-github/github"
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestClassifyError(t *testing.T) {
+	newResponse := func(statusCode int) *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+		return &http.Response{StatusCode: statusCode, Request: req}
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"http 401", &HTTPError{StatusCode: http.StatusUnauthorized}, ErrAuth},
+		{"http 403", &HTTPError{StatusCode: http.StatusForbidden}, ErrAuth},
+		{"http 404", &HTTPError{StatusCode: http.StatusNotFound}, ErrNotFound},
+		{"http 429", &HTTPError{StatusCode: http.StatusTooManyRequests}, ErrRateLimited},
+		{"http 500", &HTTPError{StatusCode: http.StatusInternalServerError}, nil},
+		{"github 404", &github.ErrorResponse{Response: newResponse(http.StatusNotFound)}, ErrNotFound},
+		{"github rate limit", &github.RateLimitError{Response: newResponse(http.StatusForbidden)}, ErrRateLimited},
+		{"github two factor", &github.TwoFactorAuthError{Response: newResponse(http.StatusUnauthorized)}, ErrAuth},
+		{"gitlab 401", &gitlab.ErrorResponse{Response: newResponse(http.StatusUnauthorized)}, ErrAuth},
+		{"wrapped", fmt.Errorf("get snippet: %w", &HTTPError{StatusCode: http.StatusNotFound}), ErrNotFound},
+		{"local", fmt.Errorf("open: %w", os.ErrNotExist), ErrNotFound},
+		{"timeout", context.DeadlineExceeded, ErrNetwork},
+		{"url", &url.Error{Op: "Get", URL: "https://example.com/", Err: errors.New("connection refused")}, ErrNetwork},
+		{"canceled", &url.Error{Op: "Get", URL: "https://example.com/", Err: context.Canceled}, nil},
+		{"unknown", errors.New("unknown"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyError(tt.err)
+
+			for _, kind := range []error{ErrAuth, ErrNotFound, ErrRateLimited, ErrNetwork} {
+				if want := kind == tt.want; errors.Is(got, kind) != want {
+					t.Errorf("errors.Is(classifyError(), %v) = %v, want %v", kind, !want, want)
+				}
+			}
+
+			// the original error and its message are kept
+			if !errors.Is(got, tt.err) || got.Error() != tt.err.Error() {
+				t.Errorf("classifyError() = %v, want to wrap %v", got, tt.err)
+			}
+		})
+	}
+
+	if classifyError(nil) != nil {
+		t.Error("classifyError(nil) is not nil")
+	}
+
+	// already classified error is not wrapped again
+	err := classifyError(&HTTPError{StatusCode: http.StatusNotFound})
+	if got := classifyError(err); got != err {
+		t.Errorf("classifyError() of classified error = %#v, want %#v", got, err)
+	}
+}
+
+func TestListError(t *testing.T) {
+	authErr := classifyError(&HTTPError{StatusCode: http.StatusUnauthorized, Message: "bad token"})
+	err := error(ListError{
+		"gitlab.com": errors.New("timeout"),
+		"github.com": authErr,
+	})
+
+	if want := "github.com: " + authErr.Error() + "\ngitlab.com: timeout"; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	if !errors.Is(err, ErrAuth) || errors.Is(err, ErrNotFound) {
+		t.Errorf("errors.Is() of ListError does not match the errors of platforms")
+	}
+}
@@ -7,64 +7,98 @@ package client
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/github"
 	"golang.org/x/oauth2"
 )
 
 type GistClient struct {
-	ctx          context.Context
 	client       *github.Client
+	Url          string
 	User         string
 	FilterKey    string
 	PlatformName string
+
+	// scopes of the access token. got from X-OAuth-Scopes header in Init.
+	scopes []string
+
+	// ssl, proxy and retry
+	httpOption httpOption
 }
 
+// gistListConcurrency is the number of gist list pages fetched at the same time.
+const gistListConcurrency = 4
+
 var (
 	GistIsSecret = Visibility{code: "secret", num: 0}
 	GistIsPublic = Visibility{code: "public", num: 1}
 )
 
 // Init
-func (g *GistClient) Init(token string) (err error) {
-	// create ctx
-	g.ctx = context.Background()
+// If u is empty, github.com is used. Otherwise, u is the API url of GitHub Enterprise Server. ex) https://github.example.com/api/v3/
+func (g *GistClient) Init(ctx context.Context, u, uploadURL, token string) (err error) {
+	// Create http Client
+	h, err := newHTTPClient(g.httpOption)
+	if err != nil {
+		return
+	}
 
 	// Create oAuth2 Client
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
-	tc := oauth2.NewClient(g.ctx, ts)
+	tc := oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, h), ts)
 
 	// Create Client
-	g.client = github.NewClient(tc)
+	host := "gist.github.com"
+	if u == "" {
+		g.client = github.NewClient(tc)
+	} else {
+		if uploadURL == "" {
+			uploadURL = u
+		}
+
+		g.client, err = github.NewEnterpriseClient(u, uploadURL, tc)
+		if err != nil {
+			return
+		}
+
+		host = g.client.BaseURL.Host
+	}
+	g.Url = g.client.BaseURL.String()
 
 	// Get login user
-	user, _, err := g.client.Users.Get(g.ctx, "")
+	user, resp, err := g.client.Users.Get(ctx, "")
 	if err != nil {
 		return
 	}
 	g.User = *user.Login
+	g.scopes = splitScopes(resp.Header.Get("X-OAuth-Scopes"))
 
 	// Generate PlatformName
-	// host := g.client.BaseURL.Host
-	host := "gist.github.com"
 	g.PlatformName = fmt.Sprintf("%s:%s", host, g.User)
 
 	return
 }
 
 // List
-func (g *GistClient) List(isFile, isSecret bool) (snippetList SnippetList, err error) {
-	// create gist list options
-	opt := &github.GistListOptions{
-		ListOptions: github.ListOptions{PerPage: 100},
-	}
+func (g *GistClient) List(ctx context.Context, isFile, isSecret bool) (snippetList SnippetList, err error) {
+	return g.ListSince(ctx, isFile, isSecret, time.Time{})
+}
 
+// ListSince lists gists updated at or after since. If since is zero, all gists are listed.
+func (g *GistClient) ListSince(ctx context.Context, isFile, isSecret bool, since time.Time) (snippetList SnippetList, err error) {
 	// get gistList
-	gistDataList, _, err := g.client.Gists.List(g.ctx, "", opt)
+	gistDataList, err := g.listAllGists(ctx, since)
+	if err != nil {
+		return
+	}
 
 	// create []SnipetListData
 	for _, gist := range gistDataList {
@@ -88,6 +122,8 @@ func (g *GistClient) List(isFile, isSecret bool) (snippetList SnippetList, err e
 			Title:      description,
 			URL:        gist.GetHTMLURL(),
 			Visibility: visibility,
+			Secret:     !gist.GetPublic(),
+			UpdatedAt:  gist.GetUpdatedAt(),
 		}
 
 		if isFile {
@@ -110,40 +146,85 @@ func (g *GistClient) List(isFile, isSecret bool) (snippetList SnippetList, err e
 	return snippetList, err
 }
 
-// Get
-func (g *GistClient) Get(id string) (data SnippetData, err error) {
-	gist, _, err := g.client.Gists.Get(g.ctx, id)
+// listAllGists gets all pages of gist list.
+// After the first page, the last page is known from the Link header, so the remaining pages are fetched concurrently.
+func (g *GistClient) listAllGists(ctx context.Context, since time.Time) (gists []*github.Gist, err error) {
+	// create gist list options
+	opt := &github.GistListOptions{
+		Since:       since,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
 
-	files := []SnippetFileData{}
-	for _, file := range gist.Files {
+	// get first page
+	gists, resp, err := g.client.Gists.List(ctx, "", opt)
+	if err != nil {
+		return
+	}
 
-		fd := SnippetFileData{
-			Filter:   gist.GetHTMLURL() + "/" + file.GetFilename(),
-			RawURL:   file.GetRawURL(),
-			Path:     file.GetFilename(),
-			Contents: []byte(file.GetContent()),
+	// Link header has no `last`. follow `next` one by one.
+	if resp.LastPage == 0 {
+		for resp.NextPage != 0 {
+			opt.Page = resp.NextPage
+
+			var page []*github.Gist
+			page, resp, err = g.client.Gists.List(ctx, "", opt)
+			if err != nil {
+				return
+			}
+
+			gists = append(gists, page...)
 		}
 
-		files = append(files, fd)
+		return
 	}
 
-	visibility := GistIsSecret
-	if gist.GetPublic() {
-		visibility = GistIsPublic
+	// get remaining pages concurrently
+	pages := make([][]*github.Gist, resp.LastPage+1)
+	errs := make([]error, resp.LastPage+1)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, gistListConcurrency)
+	for p := 2; p <= resp.LastPage; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			popt := &github.GistListOptions{
+				Since:       since,
+				ListOptions: github.ListOptions{Page: p, PerPage: opt.PerPage},
+			}
+			pages[p], _, errs[p] = g.client.Gists.List(ctx, "", popt)
+		}(p)
 	}
+	wg.Wait()
 
-	data = SnippetData{
-		Title:      gist.GetDescription(),
-		URL:        gist.GetHTMLURL(),
-		Visibility: visibility,
-		Files:      files,
+	// merge pages in order
+	for p := 2; p <= resp.LastPage; p++ {
+		if errs[p] != nil {
+			return gists, errs[p]
+		}
+
+		gists = append(gists, pages[p]...)
 	}
 
 	return
 }
 
+// Get
+func (g *GistClient) Get(ctx context.Context, id string) (data SnippetData, err error) {
+	gist, _, err := g.client.Gists.Get(ctx, id)
+	if err != nil {
+		return
+	}
+
+	return createGistSnippetData(gist), nil
+}
+
 // Create
-func (g *GistClient) Create(data SnippetData) (gist SnippetClient, err error) {
+func (g *GistClient) Create(ctx context.Context, data SnippetData) (snippet SnippetData, err error) {
 	// set default visiblity
 	if data.Visibility == (Visibility{}) {
 		data.Visibility = GistIsSecret
@@ -159,48 +240,187 @@ func (g *GistClient) Create(data SnippetData) (gist SnippetClient, err error) {
 	files := createGithubGistFiles(data.Files)
 
 	// create gist
-	gist, _, err = g.client.Gists.Create(
-		g.ctx,
+	gist, _, err := g.client.Gists.Create(
+		ctx,
 		&github.Gist{
 			Description: &data.Title,
 			Files:       files,
 			Public:      &isPublic,
 		})
+	if err != nil {
+		return
+	}
 
-	return gist, err
+	return createGistSnippetData(gist), nil
 }
 
 // Update
-func (g *GistClient) Update(id string, data SnippetData) (gist SnippetClient, err error) {
+func (g *GistClient) Update(ctx context.Context, id string, data SnippetData) (snippet SnippetData, err error) {
 	// set visiblity
 	isPublic := false
 	if data.Visibility == GistIsPublic {
 		isPublic = true
 	}
 
-	// create files
-	files := createGithubGistFiles(data.Files)
+	// create files. deleted files are sent as null, that go-github's Gist can not express.
+	files := map[github.GistFilename]*github.GistFile{}
+	for name, f := range createGithubGistFiles(data.Files) {
+		files[name] = &f
+	}
+	for _, d := range data.Files {
+		if d.Deleted {
+			files[github.GistFilename(d.Path)] = nil
+		}
+	}
 
 	// update gist
-	gist, _, err = g.client.Gists.Edit(
-		g.ctx,
-		id,
-		&github.Gist{
-			Description: &data.Title,
-			Files:       files,
-			Public:      &isPublic,
-		})
+	req, err := g.client.NewRequest(http.MethodPatch, "gists/"+id, &gistEditRequest{
+		Description: &data.Title,
+		Files:       files,
+		Public:      &isPublic,
+	})
+	if err != nil {
+		return
+	}
+
+	gist := &github.Gist{}
+	if _, err = g.client.Do(ctx, req, gist); err != nil {
+		return
+	}
+
+	return createGistSnippetData(gist), nil
+}
 
-	return gist, err
+// gistEditRequest is the request body of editing gist.
+type gistEditRequest struct {
+	Description *string                                  `json:"description,omitempty"`
+	Files       map[github.GistFilename]*github.GistFile `json:"files,omitempty"`
+	Public      *bool                                    `json:"public,omitempty"`
 }
 
 // Delete
-func (g *GistClient) Delete(id string) (err error) {
-	_, err = g.client.Gists.Delete(g.ctx, id)
+func (g *GistClient) Delete(ctx context.Context, id string) (err error) {
+	_, err = g.client.Gists.Delete(ctx, id)
+
+	return
+}
+
+// ListComments
+func (g *GistClient) ListComments(ctx context.Context, id string) (comments []SnippetComment, err error) {
+	opt := &github.ListOptions{PerPage: 100}
+
+	for {
+		gistComments, resp, ferr := g.client.Gists.ListComments(ctx, id, opt)
+		if ferr != nil {
+			return comments, ferr
+		}
+
+		for _, gc := range gistComments {
+			comments = append(comments, createGistSnippetComment(gc))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
 
 	return
 }
 
+// AddComment
+func (g *GistClient) AddComment(ctx context.Context, id, body string) (comment SnippetComment, err error) {
+	gc, _, err := g.client.Gists.CreateComment(ctx, id, &github.GistComment{Body: &body})
+	if err != nil {
+		return
+	}
+
+	return createGistSnippetComment(gc), nil
+}
+
+// EditComment
+func (g *GistClient) EditComment(ctx context.Context, id, commentId, body string) (comment SnippetComment, err error) {
+	intCommentId, err := strconv.ParseInt(commentId, 10, 64)
+	if err != nil {
+		return
+	}
+
+	gc, _, err := g.client.Gists.EditComment(ctx, id, intCommentId, &github.GistComment{Body: &body})
+	if err != nil {
+		return
+	}
+
+	return createGistSnippetComment(gc), nil
+}
+
+// DeleteComment
+func (g *GistClient) DeleteComment(ctx context.Context, id, commentId string) (err error) {
+	intCommentId, err := strconv.ParseInt(commentId, 10, 64)
+	if err != nil {
+		return
+	}
+
+	_, err = g.client.Gists.DeleteComment(ctx, id, intCommentId)
+
+	return
+}
+
+// ListRevisions
+func (g *GistClient) ListRevisions(ctx context.Context, id string) (revisions []SnippetRevision, err error) {
+	opt := &github.ListOptions{PerPage: 100}
+
+	for {
+		commits, resp, ferr := g.client.Gists.ListCommits(ctx, id, opt)
+		if ferr != nil {
+			return revisions, ferr
+		}
+
+		for _, c := range commits {
+			revisions = append(revisions, SnippetRevision{
+				Revision:  c.GetVersion(),
+				Author:    c.GetUser().GetLogin(),
+				Message:   fmt.Sprintf("+%d -%d", c.GetChangeStatus().GetAdditions(), c.GetChangeStatus().GetDeletions()),
+				CreatedAt: c.GetCommittedAt().Time,
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return
+}
+
+// GetRevision
+func (g *GistClient) GetRevision(ctx context.Context, id, revision string) (snippet SnippetData, err error) {
+	revisions, err := g.ListRevisions(ctx, id)
+	if err != nil {
+		return
+	}
+
+	revision, err = resolveRevision(revisions, revision)
+	if err != nil {
+		return
+	}
+
+	gist, _, err := g.client.Gists.GetRevision(ctx, id, revision)
+	if err != nil {
+		return
+	}
+
+	return createGistSnippetData(gist), nil
+}
+
+// ListTokenScopes
+// Fine-grained access tokens have no scopes, so the list is empty.
+func (g *GistClient) ListTokenScopes(ctx context.Context) (scopes []string, err error) {
+	return g.scopes, nil
+}
+
 // GetPlatformName
 func (g *GistClient) GetPlatformName() string {
 	return g.PlatformName
@@ -230,12 +450,64 @@ func (g *GistClient) VisibilityList() (visibilityList []Visibility) {
 func createGithubGistFiles(data []SnippetFileData) (files map[github.GistFilename]github.GistFile) {
 	files = map[github.GistFilename]github.GistFile{}
 	for _, d := range data {
+		if d.Deleted {
+			continue
+		}
+
 		content := string(d.Contents)
-		files[github.GistFilename(d.Path)] =
+
+		// renamed file is keyed by the previous filename.
+		key := d.Path
+		if d.PreviousPath != "" {
+			key = d.PreviousPath
+		}
+
+		files[github.GistFilename(key)] =
 			github.GistFile{
-				Content: github.String(content),
+				Filename: github.String(d.Path),
+				Content:  github.String(content),
 			}
 	}
 
 	return
 }
+
+// createGistSnippetData
+func createGistSnippetData(gist *github.Gist) SnippetData {
+	files := []SnippetFileData{}
+	for _, file := range gist.Files {
+		fd := SnippetFileData{
+			Filter:   gist.GetHTMLURL() + "/" + file.GetFilename(),
+			RawURL:   file.GetRawURL(),
+			Path:     file.GetFilename(),
+			Contents: []byte(file.GetContent()),
+		}
+
+		files = append(files, fd)
+	}
+
+	visibility := GistIsSecret
+	if gist.GetPublic() {
+		visibility = GistIsPublic
+	}
+
+	return SnippetData{
+		Id:         gist.GetID(),
+		Title:      gist.GetDescription(),
+		URL:        gist.GetHTMLURL(),
+		Visibility: visibility,
+		Files:      files,
+		CreatedAt:  gist.GetCreatedAt(),
+		UpdatedAt:  gist.GetUpdatedAt(),
+	}
+}
+
+// createGistSnippetComment
+func createGistSnippetComment(gc *github.GistComment) SnippetComment {
+	return SnippetComment{
+		Id:        strconv.FormatInt(gc.GetID(), 10),
+		Author:    gc.GetUser().GetLogin(),
+		Body:      gc.GetBody(),
+		CreatedAt: gc.GetCreatedAt(),
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/github"
+)
+
+func TestGistClientUpdate(t *testing.T) {
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || r.URL.Path != "/gists/abc" {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+
+		json.NewDecoder(r.Body).Decode(&body)
+		writeJSON(w, map[string]interface{}{
+			"id":       "abc",
+			"html_url": "https://gist.github.com/abc",
+			"files":    map[string]interface{}{"c.sh": map[string]string{"filename": "c.sh", "content": "echo c"}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	g := &GistClient{client: github.NewClient(nil)}
+	g.client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	snippet, err := g.Update(context.Background(), "abc", SnippetData{
+		Title: "title",
+		Files: []SnippetFileData{
+			{Path: "c.sh", PreviousPath: "a.sh", Contents: []byte("echo c")},
+			{Path: "b.sh", Deleted: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if snippet.Id != "abc" || len(snippet.Files) != 1 {
+		t.Errorf("Update() = %+v", snippet)
+	}
+
+	// renamed file is keyed by the previous name, and deleted file is null
+	files, _ := body["files"].(map[string]interface{})
+	renamed, _ := files["a.sh"].(map[string]interface{})
+	deleted, isDeleted := files["b.sh"]
+	if len(files) != 2 || renamed["filename"] != "c.sh" || renamed["content"] != "echo c" || !isDeleted || deleted != nil {
+		t.Errorf("Update() request files = %v", files)
+	}
+	if body["description"] != "title" {
+		t.Errorf("Update() request description = %v", body["description"])
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// runGit runs git command in dir, and returns the stdout. env is added to the environment.
+func runGit(ctx context.Context, dir string, env []string, args ...string) (out []byte, err error) {
+	var stdout, stderr bytes.Buffer
+
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err = cmd.Run(); err != nil {
+		// ctx is canceled
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		return nil, fmt.Errorf("git %s: %s", getGitSubcommand(args), strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// getGitSubcommand returns the subcommand in args, skipping the global options. ex) `-C dir -c user.name=snipt commit` => commit
+func getGitSubcommand(args []string) string {
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-C", "-c":
+			i++
+		default:
+			return args[i]
+		}
+	}
+
+	return ""
+}
+
+// gitLog returns the revisions of the paths in the repository, newest first.
+func gitLog(ctx context.Context, dir string, env []string, paths ...string) (revisions []SnippetRevision, err error) {
+	args := append([]string{"log", "--format=%H%x1f%an%x1f%aI%x1f%s"}, "--")
+	out, err := runGit(ctx, dir, env, append(args, paths...)...)
+	if err != nil {
+		return
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.SplitN(line, "\x1f", 4)
+		if len(fields) < 4 {
+			continue
+		}
+
+		createdAt, _ := time.Parse(time.RFC3339, fields[2])
+		revisions = append(revisions, SnippetRevision{
+			Revision:  fields[0],
+			Author:    fields[1],
+			Message:   fields[3],
+			CreatedAt: createdAt,
+		})
+	}
+
+	return
+}
+
+// gitReadTree returns the files under dir prefix at revision. Path of the files is relative to prefix.
+func gitReadTree(ctx context.Context, dir string, env []string, revision, prefix string) (files []SnippetFileData, err error) {
+	args := []string{"ls-tree", "-r", "-z", "--name-only", revision}
+	if prefix != "" {
+		args = append(args, "--", prefix+"/")
+	}
+
+	out, err := runGit(ctx, dir, env, args...)
+	if err != nil {
+		return
+	}
+
+	for _, p := range strings.Split(string(out), "\x00") {
+		if p == "" {
+			continue
+		}
+
+		contents, cerr := runGit(ctx, dir, env, "cat-file", "blob", revision+":"+p)
+		if cerr != nil {
+			return files, cerr
+		}
+
+		if prefix != "" {
+			p = strings.TrimPrefix(p, prefix+"/")
+		}
+
+		files = append(files, SnippetFileData{
+			Path:     path.Clean(p),
+			Contents: contents,
+		})
+	}
+
+	return
+}
+
+// cloneGitRepo clones the repository of u as a bare repository into a temporary directory.
+// The caller removes dir.
+func cloneGitRepo(ctx context.Context, u string, env []string) (dir string, err error) {
+	dir, err = os.MkdirTemp("", "snipt_git_")
+	if err != nil {
+		return
+	}
+
+	if _, err = runGit(ctx, "", env, "clone", "--bare", "-q", u, dir); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+
+	return
+}
+
+// newGitEnv creates the environment variables of git, with the ssl and proxy settings of opt and the http header.
+// The values are passed by GIT_CONFIG_* instead of the arguments, so that the token is not shown in the process list.
+func newGitEnv(opt httpOption, header string) (env []string) {
+	configs := [][2]string{}
+	if header != "" {
+		configs = append(configs, [2]string{"http.extraHeader", header})
+	}
+
+	if opt.Insecure {
+		configs = append(configs, [2]string{"http.sslVerify", "false"})
+	}
+
+	if opt.CAFile != "" {
+		configs = append(configs, [2]string{"http.sslCAInfo", expandHome(opt.CAFile)})
+	}
+
+	if opt.ClientCert != "" {
+		configs = append(configs, [2]string{"http.sslCert", expandHome(opt.ClientCert)})
+		configs = append(configs, [2]string{"http.sslKey", expandHome(opt.ClientKey)})
+	}
+
+	if opt.Proxy != "" {
+		if proxyUrl, err := parseProxyURL(opt.Proxy, opt.ProxyUser, opt.ProxyPass); err == nil {
+			configs = append(configs, [2]string{"http.proxy", proxyUrl.String()})
+		}
+	}
+
+	env = append(env, "GIT_TERMINAL_PROMPT=0", "GIT_CONFIG_COUNT="+strconv.Itoa(len(configs)))
+	for i, c := range configs {
+		env = append(env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, c[0]),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, c[1]),
+		)
+	}
+
+	return
+}
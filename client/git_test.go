@@ -0,0 +1,49 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestResolveRevision(t *testing.T) {
+	revisions := []SnippetRevision{{Revision: "abc123"}, {Revision: "abd456"}, {Revision: "ef7890"}}
+
+	tests := []struct {
+		prefix  string
+		want    string
+		wantErr error
+	}{
+		{"abc", "abc123", nil},
+		{"ef7890", "ef7890", nil},
+		{"ab", "", nil},
+		{"ff", "", ErrNotFound},
+		{"", "", ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		got, err := resolveRevision(revisions, tt.prefix)
+		if got != tt.want || (tt.want == "") != (err != nil) || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+			t.Errorf("resolveRevision(%q) = %q, %v, want %q, %v", tt.prefix, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestGetGitSubcommand(t *testing.T) {
+	tests := map[string]string{
+		"log --format=%H":                          "log",
+		"-C dir ls-files -- id":                    "ls-files",
+		"-C dir -c user.name=snipt commit -q -m x": "commit",
+		"-C dir": "",
+	}
+
+	for args, want := range tests {
+		if got := getGitSubcommand(strings.Fields(args)); got != want {
+			t.Errorf("getGitSubcommand(%q) = %q, want %q", args, got, want)
+		}
+	}
+}
@@ -0,0 +1,689 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GiteaClient is the client of Gitea/Forgejo.
+// Gitea has no snippet API, so snippets are stored in a dedicated repository.
+// Each snippet is a directory in the default branch, which has files and the metadata file (.snipt.json).
+type GiteaClient struct {
+	client       *http.Client
+	token        string
+	Url          string
+	User         string
+	Owner        string
+	Repo         string
+	Branch       string
+	PlatformName string
+	FilterKey    string
+
+	// html url of repository
+	htmlURL string
+
+	// repoPublic is true if the repository is public. All snippets in it are readable by anyone.
+	repoPublic bool
+
+	// ssl, proxy and retry
+	httpOption httpOption
+}
+
+var (
+	GiteaIsPrivate = Visibility{code: "private", num: 0}
+	GiteaIsPublic  = Visibility{code: "public", num: 1}
+)
+
+// giteaConcurrency is the number of metadata files fetched at the same time.
+const giteaConcurrency = 8
+
+// giteaTreeEntry
+type giteaTreeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	SHA  string `json:"sha"`
+}
+
+// giteaSnippet is a snippet directory in the repository.
+type giteaSnippet struct {
+	id      string
+	meta    snippetMetadata
+	metaSHA string
+	files   []giteaTreeEntry // Path is relative to the snippet directory
+}
+
+// giteaChangeFileOperation
+type giteaChangeFileOperation struct {
+	Operation string `json:"operation"`
+	Path      string `json:"path"`
+	Content   string `json:"content,omitempty"`
+	FromPath  string `json:"from_path,omitempty"`
+	SHA       string `json:"sha,omitempty"`
+}
+
+// giteaChangeFilesOptions
+type giteaChangeFilesOptions struct {
+	Branch  string                     `json:"branch,omitempty"`
+	Message string                     `json:"message,omitempty"`
+	Files   []giteaChangeFileOperation `json:"files"`
+}
+
+// Init
+func (g *GiteaClient) Init(ctx context.Context, u, token, repo string) (err error) {
+	// Create http Client
+	g.client, err = newHTTPClient(g.httpOption)
+	if err != nil {
+		return
+	}
+
+	g.Url = strings.TrimSuffix(u, "/")
+	g.token = token
+
+	// get login user
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err = g.request(ctx, http.MethodGet, "/user", nil, nil, &user); err != nil {
+		return
+	}
+	g.User = user.Login
+
+	// set repository. `repo` is `owner/repo` or `repo` owned by login user.
+	g.Owner, g.Repo = g.User, repo
+	if i := strings.Index(repo, "/"); i >= 0 {
+		g.Owner, g.Repo = repo[:i], repo[i+1:]
+	}
+
+	var r struct {
+		DefaultBranch string `json:"default_branch"`
+		HTMLURL       string `json:"html_url"`
+		Private       bool   `json:"private"`
+	}
+	if err = g.request(ctx, http.MethodGet, g.repoPath(""), nil, nil, &r); err != nil {
+		return
+	}
+	g.Branch = r.DefaultBranch
+	g.htmlURL = r.HTMLURL
+	g.repoPublic = !r.Private
+
+	// Generate PlatformName
+	pu, err := url.Parse(g.Url)
+	if err != nil {
+		return
+	}
+	g.PlatformName = fmt.Sprintf("%s:%s/%s", pu.Host, g.Owner, g.Repo)
+
+	return
+}
+
+// List
+func (g *GiteaClient) List(ctx context.Context, isFile, isSecret bool) (snippetList SnippetList, err error) {
+	snippets, err := g.listSnippets(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, s := range snippets {
+		visibility := g.getVisibility(s.meta)
+		if !isSecret && visibility != GiteaIsPublic.GetCode() {
+			continue
+		}
+
+		// get Description
+		title := replaceNewline(s.meta.Title, "\\n")
+
+		data := SnippetListData{
+			Client:     g,
+			Platform:   g.PlatformName,
+			Id:         s.id,
+			Title:      title,
+			URL:        g.snippetURL(s.id),
+			Visibility: visibility,
+			Secret:     visibility != GiteaIsPublic.GetCode(),
+			UpdatedAt:  s.meta.UpdatedAt,
+		}
+
+		if isFile {
+			for _, f := range s.files {
+				fd := data
+				fd.URL, _ = url.JoinPath(fd.URL, f.Path)
+				fd.RawURL = g.rawURL(s.id, f.Path)
+				snippetList = append(snippetList, &fd)
+			}
+		} else {
+			snippetList = append(snippetList, &data)
+		}
+	}
+
+	return
+}
+
+// Get
+func (g *GiteaClient) Get(ctx context.Context, id string) (snippet SnippetData, err error) {
+	s, err := g.getSnippet(ctx, id)
+	if err != nil {
+		return
+	}
+
+	paths := []string{}
+	for _, f := range s.files {
+		paths = append(paths, f.Path)
+	}
+	snippet = g.createSnippetData(id, s.meta, paths)
+
+	// get contents of files
+	for i, f := range s.files {
+		content, ferr := g.getBlob(ctx, f.SHA)
+		if ferr != nil {
+			return snippet, ferr
+		}
+
+		snippet.Files[i].Contents = content
+	}
+
+	return
+}
+
+// Create
+func (g *GiteaClient) Create(ctx context.Context, data SnippetData) (snippet SnippetData, err error) {
+	// set default visiblity
+	if data.Visibility == (Visibility{}) {
+		data.Visibility = GiteaIsPrivate
+		if g.repoPublic {
+			data.Visibility = GiteaIsPublic
+		}
+	}
+
+	if err = g.checkVisibility(data.Visibility); err != nil {
+		return
+	}
+
+	id := newSnippetId()
+	now := time.Now().UTC()
+
+	// create metadata
+	metadata := snippetMetadata{
+		Title:       data.Title,
+		Description: data.Description,
+		Visibility:  data.Visibility.GetCode(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	meta, err := marshalSnippetMetadata(metadata)
+	if err != nil {
+		return
+	}
+
+	ops := []giteaChangeFileOperation{
+		{
+			Operation: "create",
+			Path:      path.Join(id, snippetMetadataFile),
+			Content:   base64.StdEncoding.EncodeToString(meta),
+		},
+	}
+
+	for _, f := range data.Files {
+		ops = append(ops, giteaChangeFileOperation{
+			Operation: "create",
+			Path:      path.Join(id, f.Path),
+			Content:   base64.StdEncoding.EncodeToString(f.Contents),
+		})
+	}
+
+	if err = g.changeFiles(ctx, "create snippet "+id, ops); err != nil {
+		return
+	}
+
+	paths := []string{}
+	for _, f := range data.Files {
+		paths = append(paths, f.Path)
+	}
+
+	return g.createSnippetData(id, metadata, paths), nil
+}
+
+// Update
+func (g *GiteaClient) Update(ctx context.Context, id string, data SnippetData) (snippet SnippetData, err error) {
+	s, err := g.getSnippet(ctx, id)
+	if err != nil {
+		return
+	}
+
+	// update metadata
+	s.meta.Title = data.Title
+	s.meta.Description = data.Description
+	if data.Visibility != (Visibility{}) {
+		if err = g.checkVisibility(data.Visibility); err != nil {
+			return
+		}
+
+		s.meta.Visibility = data.Visibility.GetCode()
+	}
+	s.meta.UpdatedAt = time.Now().UTC()
+
+	meta, err := marshalSnippetMetadata(s.meta)
+	if err != nil {
+		return
+	}
+
+	ops := []giteaChangeFileOperation{
+		{
+			Operation: "update",
+			Path:      path.Join(id, snippetMetadataFile),
+			Content:   base64.StdEncoding.EncodeToString(meta),
+			SHA:       s.metaSHA,
+		},
+	}
+
+	// get sha of current files
+	shaList := map[string]string{}
+	for _, f := range s.files {
+		shaList[f.Path] = f.SHA
+	}
+
+	// paths of files after update
+	pathList := map[string]bool{}
+	for _, f := range s.files {
+		pathList[f.Path] = true
+	}
+
+	for _, f := range data.Files {
+		// deleted file. the file not in the snippet is ignored.
+		if f.Deleted {
+			if sha, ok := shaList[f.Path]; ok {
+				ops = append(ops, giteaChangeFileOperation{Operation: "delete", Path: path.Join(id, f.Path), SHA: sha})
+				delete(pathList, f.Path)
+			}
+			continue
+		}
+
+		op := giteaChangeFileOperation{
+			Operation: "create",
+			Path:      path.Join(id, f.Path),
+			Content:   base64.StdEncoding.EncodeToString(f.Contents),
+		}
+
+		if sha, ok := shaList[f.PreviousPath]; ok && f.PreviousPath != "" && f.PreviousPath != f.Path {
+			// renamed file
+			op.Operation = "update"
+			op.FromPath = path.Join(id, f.PreviousPath)
+			op.SHA = sha
+		} else if sha, ok := shaList[f.Path]; ok {
+			op.Operation = "update"
+			op.SHA = sha
+		}
+
+		ops = append(ops, op)
+
+		if op.FromPath != "" {
+			delete(pathList, f.PreviousPath)
+		}
+		pathList[f.Path] = true
+	}
+
+	if err = g.changeFiles(ctx, "update snippet "+id, ops); err != nil {
+		return
+	}
+
+	paths := []string{}
+	for p := range pathList {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	return g.createSnippetData(id, s.meta, paths), nil
+}
+
+// Delete
+func (g *GiteaClient) Delete(ctx context.Context, id string) (err error) {
+	s, err := g.getSnippet(ctx, id)
+	if err != nil {
+		return
+	}
+
+	ops := []giteaChangeFileOperation{
+		{
+			Operation: "delete",
+			Path:      path.Join(id, snippetMetadataFile),
+			SHA:       s.metaSHA,
+		},
+	}
+
+	for _, f := range s.files {
+		ops = append(ops, giteaChangeFileOperation{
+			Operation: "delete",
+			Path:      path.Join(id, f.Path),
+			SHA:       f.SHA,
+		})
+	}
+
+	return g.changeFiles(ctx, "delete snippet "+id, ops)
+}
+
+// GetPlatformName
+func (g *GiteaClient) GetPlatformName() string {
+	return g.PlatformName
+}
+
+// GetFilterKey
+func (g *GiteaClient) GetFilterKey() string {
+	return g.FilterKey
+}
+
+// SetFilterKey
+func (g *GiteaClient) SetFilterKey(key string) {
+	g.FilterKey = key
+}
+
+// VisibilityList
+// In public repository, only public is available.
+func (g *GiteaClient) VisibilityList() (visibilityList []Visibility) {
+	if g.repoPublic {
+		return []Visibility{GiteaIsPublic}
+	}
+
+	visibilityList = []Visibility{
+		GiteaIsPrivate,
+		GiteaIsPublic,
+	}
+
+	return
+}
+
+// getVisibility returns the visibility code of the snippet.
+// The snippets in public repository are public, even if the metadata says private.
+func (g *GiteaClient) getVisibility(meta snippetMetadata) string {
+	if g.repoPublic {
+		return GiteaIsPublic.GetCode()
+	}
+
+	return meta.Visibility
+}
+
+// checkVisibility refuses private snippet in public repository, because it is readable by anyone.
+func (g *GiteaClient) checkVisibility(v Visibility) error {
+	if g.repoPublic && v != GiteaIsPublic {
+		return fmt.Errorf("cannot store %s snippet in public repository %s/%s. make the repository private", v.GetCode(), g.Owner, g.Repo)
+	}
+
+	return nil
+}
+
+// listSnippets gets all snippet directories and their metadata.
+func (g *GiteaClient) listSnippets(ctx context.Context) (snippets []*giteaSnippet, err error) {
+	tree, err := g.getTree(ctx)
+	if err != nil {
+		return
+	}
+
+	snippets = createGiteaSnippets(tree)
+
+	// get metadata concurrently
+	errs := make([]error, len(snippets))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, giteaConcurrency)
+	for i, s := range snippets {
+		wg.Add(1)
+		go func(i int, s *giteaSnippet) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			errs[i] = g.getMetadata(ctx, s)
+		}(i, s)
+	}
+	wg.Wait()
+
+	for _, e := range errs {
+		if e != nil {
+			return snippets, e
+		}
+	}
+
+	return
+}
+
+// getSnippet
+func (g *GiteaClient) getSnippet(ctx context.Context, id string) (s *giteaSnippet, err error) {
+	tree, err := g.getTree(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, sn := range createGiteaSnippets(tree) {
+		if sn.id == id {
+			err = g.getMetadata(ctx, sn)
+			return sn, err
+		}
+	}
+
+	err = &HTTPError{
+		Method:     http.MethodGet,
+		URL:        g.snippetURL(id),
+		StatusCode: http.StatusNotFound,
+		Message:    "snippet not found",
+	}
+
+	return
+}
+
+// getMetadata
+func (g *GiteaClient) getMetadata(ctx context.Context, s *giteaSnippet) (err error) {
+	data, err := g.getBlob(ctx, s.metaSHA)
+	if err != nil {
+		return
+	}
+
+	return json.Unmarshal(data, &s.meta)
+}
+
+// getTree gets all entries of the default branch.
+func (g *GiteaClient) getTree(ctx context.Context) (entries []giteaTreeEntry, err error) {
+	// get head commit of branch
+	var branch struct {
+		Commit struct {
+			ID string `json:"id"`
+		} `json:"commit"`
+	}
+
+	err = g.request(ctx, http.MethodGet, g.repoPath("/branches/"+url.PathEscape(g.Branch)), nil, nil, &branch)
+	if he, ok := err.(*HTTPError); ok && he.StatusCode == http.StatusNotFound {
+		// empty repository
+		return nil, nil
+	}
+	if err != nil {
+		return
+	}
+
+	for page := 1; ; page++ {
+		var tree struct {
+			Tree      []giteaTreeEntry `json:"tree"`
+			Truncated bool             `json:"truncated"`
+		}
+
+		query := url.Values{}
+		query.Set("recursive", "true")
+		query.Set("page", strconv.Itoa(page))
+		query.Set("per_page", "1000")
+
+		err = g.request(ctx, http.MethodGet, g.repoPath("/git/trees/"+branch.Commit.ID), query, nil, &tree)
+		if err != nil {
+			return
+		}
+
+		entries = append(entries, tree.Tree...)
+
+		if !tree.Truncated || len(tree.Tree) == 0 {
+			break
+		}
+	}
+
+	return
+}
+
+// getBlob
+func (g *GiteaClient) getBlob(ctx context.Context, sha string) (content []byte, err error) {
+	var blob struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+
+	if err = g.request(ctx, http.MethodGet, g.repoPath("/git/blobs/"+sha), nil, nil, &blob); err != nil {
+		return
+	}
+
+	if blob.Encoding != "base64" {
+		return []byte(blob.Content), nil
+	}
+
+	return base64.StdEncoding.DecodeString(blob.Content)
+}
+
+// changeFiles commits the file operations to the default branch.
+func (g *GiteaClient) changeFiles(ctx context.Context, message string, ops []giteaChangeFileOperation) (err error) {
+	opt := giteaChangeFilesOptions{
+		Branch:  g.Branch,
+		Message: "snipt: " + message,
+		Files:   ops,
+	}
+
+	return g.request(ctx, http.MethodPost, g.repoPath("/contents"), nil, opt, nil)
+}
+
+// request calls Gitea REST API.
+func (g *GiteaClient) request(ctx context.Context, method, p string, query url.Values, body, out interface{}) (err error) {
+	u := g.Url + "/api/v1" + p
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var r io.Reader
+	if body != nil {
+		data, merr := json.Marshal(body)
+		if merr != nil {
+			return merr
+		}
+		r = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, r)
+	if err != nil {
+		return
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "token "+g.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return doHTTPRequest(g.client, req, out)
+}
+
+// repoPath
+func (g *GiteaClient) repoPath(p string) string {
+	return "/repos/" + url.PathEscape(g.Owner) + "/" + url.PathEscape(g.Repo) + p
+}
+
+// snippetURL
+func (g *GiteaClient) snippetURL(id string) string {
+	u, _ := url.JoinPath(g.htmlURL, "src", "branch", g.Branch, id)
+	return u
+}
+
+// rawURL
+func (g *GiteaClient) rawURL(id, p string) string {
+	u, _ := url.JoinPath(g.htmlURL, "raw", "branch", g.Branch, id, p)
+	return u
+}
+
+// createSnippetData creates SnippetData without the contents of files.
+func (g *GiteaClient) createSnippetData(id string, meta snippetMetadata, paths []string) SnippetData {
+	files := []SnippetFileData{}
+	for _, p := range paths {
+		files = append(files, SnippetFileData{
+			Filter: g.snippetURL(id) + "/" + p,
+			RawURL: g.rawURL(id, p),
+			Path:   p,
+		})
+	}
+
+	return SnippetData{
+		Id:          id,
+		Title:       meta.Title,
+		Description: meta.Description,
+		URL:         g.snippetURL(id),
+		Visibility:  getGiteaVisibilityFromString(g.getVisibility(meta)),
+		Files:       files,
+		CreatedAt:   meta.CreatedAt,
+		UpdatedAt:   meta.UpdatedAt,
+	}
+}
+
+// createGiteaSnippets groups the tree entries by snippet directory.
+// Directories without the metadata file are not snippets.
+func createGiteaSnippets(tree []giteaTreeEntry) (snippets []*giteaSnippet) {
+	snippetMap := map[string]*giteaSnippet{}
+	files := map[string][]giteaTreeEntry{}
+
+	for _, e := range tree {
+		if e.Type != "blob" {
+			continue
+		}
+
+		i := strings.Index(e.Path, "/")
+		if i <= 0 {
+			continue
+		}
+
+		id, p := e.Path[:i], e.Path[i+1:]
+		if p == snippetMetadataFile {
+			snippetMap[id] = &giteaSnippet{id: id, metaSHA: e.SHA}
+			continue
+		}
+
+		files[id] = append(files[id], giteaTreeEntry{Path: p, Type: e.Type, SHA: e.SHA})
+	}
+
+	for id, s := range snippetMap {
+		s.files = files[id]
+		snippets = append(snippets, s)
+	}
+
+	sort.Slice(snippets, func(i, j int) bool {
+		return snippets[i].id < snippets[j].id
+	})
+
+	return
+}
+
+// getGiteaVisibilityFromString
+func getGiteaVisibilityFromString(s string) (v Visibility) {
+	switch s {
+	case GiteaIsPublic.GetCode():
+		v = GiteaIsPublic
+	default:
+		v = GiteaIsPrivate
+	}
+
+	return
+}
@@ -0,0 +1,316 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeGitea is the stand-in of Gitea REST API used by GiteaClient.
+// The repository `owner/snippets` is kept in memory as path -> contents.
+type fakeGitea struct {
+	m       sync.Mutex
+	private bool
+	files   map[string][]byte
+	commit  int
+	srv     *httptest.Server
+}
+
+// newFakeGitea
+func newFakeGitea(t *testing.T, private bool) *fakeGitea {
+	f := &fakeGitea{private: private, files: map[string][]byte{}}
+	f.srv = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.srv.Close)
+
+	return f
+}
+
+// gitSHA
+func gitSHA(data []byte) string {
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (f *fakeGitea) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	if r.Header.Get("Authorization") != "token secret" {
+		http.Error(w, `{"message":"token is required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	const repo = "/api/v1/repos/owner/snippets"
+	p := r.URL.Path
+
+	switch {
+	case r.Method == http.MethodGet && p == "/api/v1/user":
+		writeJSON(w, map[string]string{"login": "owner"})
+
+	case r.Method == http.MethodGet && p == repo:
+		writeJSON(w, map[string]interface{}{
+			"default_branch": "main",
+			"html_url":       f.srv.URL + "/owner/snippets",
+			"private":        f.private,
+		})
+
+	case r.Method == http.MethodGet && p == repo+"/branches/main":
+		writeJSON(w, map[string]interface{}{"commit": map[string]string{"id": strconv.Itoa(f.commit)}})
+
+	case r.Method == http.MethodGet && strings.HasPrefix(p, repo+"/git/trees/"):
+		paths := []string{}
+		for fp := range f.files {
+			paths = append(paths, fp)
+		}
+		sort.Strings(paths)
+
+		tree := []giteaTreeEntry{}
+		for _, fp := range paths {
+			tree = append(tree, giteaTreeEntry{Path: fp, Type: "blob", SHA: gitSHA(f.files[fp])})
+		}
+		writeJSON(w, map[string]interface{}{"tree": tree, "truncated": false})
+
+	case r.Method == http.MethodGet && strings.HasPrefix(p, repo+"/git/blobs/"):
+		sha := strings.TrimPrefix(p, repo+"/git/blobs/")
+		for _, data := range f.files {
+			if gitSHA(data) == sha {
+				writeJSON(w, map[string]string{"content": base64.StdEncoding.EncodeToString(data), "encoding": "base64"})
+				return
+			}
+		}
+		http.Error(w, `{"message":"blob not found"}`, http.StatusNotFound)
+
+	case r.Method == http.MethodPost && p == repo+"/contents":
+		var opt giteaChangeFilesOptions
+		if err := json.NewDecoder(r.Body).Decode(&opt); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if opt.Branch != "main" {
+			http.Error(w, `{"message":"branch not found"}`, http.StatusNotFound)
+			return
+		}
+
+		for _, op := range opt.Files {
+			current, exists := f.files[op.Path]
+			if op.FromPath != "" {
+				current, exists = f.files[op.FromPath]
+			}
+
+			// create needs no file, update and delete need the sha of current file
+			if (op.Operation == "create") == exists || (op.Operation != "create" && gitSHA(current) != op.SHA) {
+				http.Error(w, `{"message":"sha does not match: `+op.Path+`"}`, http.StatusUnprocessableEntity)
+				return
+			}
+		}
+
+		for _, op := range opt.Files {
+			if op.FromPath != "" {
+				delete(f.files, op.FromPath)
+			}
+
+			switch op.Operation {
+			case "delete":
+				delete(f.files, op.Path)
+			default:
+				data, _ := base64.StdEncoding.DecodeString(op.Content)
+				f.files[op.Path] = data
+			}
+		}
+		f.commit++
+
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, map[string]interface{}{})
+
+	default:
+		http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+	}
+}
+
+// writeJSON
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// newTestGiteaClient
+func newTestGiteaClient(t *testing.T, f *fakeGitea) *GiteaClient {
+	g := &GiteaClient{}
+	if err := g.Init(context.Background(), f.srv.URL+"/", "secret", "snippets"); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	return g
+}
+
+func TestGiteaClientInit(t *testing.T) {
+	f := newFakeGitea(t, true)
+	g := newTestGiteaClient(t, f)
+
+	if g.Owner != "owner" || g.Repo != "snippets" || g.Branch != "main" {
+		t.Errorf("Init() owner/repo/branch = %s/%s/%s", g.Owner, g.Repo, g.Branch)
+	}
+
+	if want := strings.TrimPrefix(f.srv.URL, "http://") + ":owner/snippets"; g.GetPlatformName() != want {
+		t.Errorf("GetPlatformName() = %s, want %s", g.GetPlatformName(), want)
+	}
+
+	// invalid token
+	err := (&GiteaClient{}).Init(context.Background(), f.srv.URL, "invalid", "snippets")
+	if !errors.Is(classifyError(err), ErrAuth) {
+		t.Errorf("Init() with invalid token error = %v, want ErrAuth", err)
+	}
+}
+
+func TestGiteaClientSnippet(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeGitea(t, true)
+	g := newTestGiteaClient(t, f)
+
+	// create
+	created, err := g.Create(ctx, SnippetData{
+		Title: "title",
+		Files: []SnippetFileData{
+			{Path: "a.sh", Contents: []byte("echo a\n")},
+			{Path: "dir/b.sh", Contents: []byte("echo b\n")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	if created.Visibility != GiteaIsPrivate || len(created.Files) != 2 {
+		t.Errorf("Create() = %+v", created)
+	}
+
+	// list
+	list, err := g.List(ctx, false, true)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].Id != created.Id || list[0].URL != created.URL || !list[0].Secret {
+		t.Fatalf("List() = %+v", list)
+	}
+
+	// private snippets are hidden without isSecret
+	if list, _ = g.List(ctx, false, false); len(list) != 0 {
+		t.Errorf("List() without secret = %d snippets, want 0", len(list))
+	}
+
+	// list files
+	if list, _ = g.List(ctx, true, true); len(list) != 2 || list[1].URL != created.URL+"/dir/b.sh" {
+		t.Errorf("List() of files = %+v", list)
+	}
+
+	// get
+	snippet, err := g.Get(ctx, created.Id)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if snippet.Title != "title" || len(snippet.Files) != 2 || string(snippet.Files[0].Contents) != "echo a\n" {
+		t.Errorf("Get() = %+v", snippet)
+	}
+
+	// update, rename and add file
+	updated, err := g.Update(ctx, created.Id, SnippetData{
+		Title:      "new title",
+		Visibility: GiteaIsPublic,
+		Files: []SnippetFileData{
+			{Path: "c.sh", PreviousPath: "a.sh", Contents: []byte("echo c\n")},
+			{Path: "d.sh", Contents: []byte("echo d\n")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	var paths []string
+	for _, file := range updated.Files {
+		paths = append(paths, file.Path)
+	}
+	if strings.Join(paths, ",") != "c.sh,d.sh,dir/b.sh" || updated.Visibility != GiteaIsPublic {
+		t.Errorf("Update() paths = %v, visibility = %v", paths, updated.Visibility)
+	}
+
+	if _, ok := f.files[created.Id+"/a.sh"]; ok {
+		t.Error("Update() did not remove the renamed file")
+	}
+
+	// delete file
+	updated, err = g.Update(ctx, created.Id, SnippetData{Title: "new title", Files: []SnippetFileData{{Path: "d.sh", Deleted: true}}})
+	if err != nil {
+		t.Fatalf("Update() with deleted file error = %v", err)
+	}
+	if _, ok := f.files[created.Id+"/d.sh"]; ok || len(updated.Files) != 2 {
+		t.Errorf("Update() did not delete the file: %+v", updated.Files)
+	}
+
+	// delete
+	if err = g.Delete(ctx, created.Id); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(f.files) != 0 {
+		t.Errorf("Delete() left files %v", f.files)
+	}
+
+	// not found
+	if _, err = g.Get(ctx, created.Id); !errors.Is(classifyError(err), ErrNotFound) {
+		t.Errorf("Get() of deleted snippet error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGiteaClientPublicRepository(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeGitea(t, false)
+	g := newTestGiteaClient(t, f)
+
+	if vl := g.VisibilityList(); len(vl) != 1 || vl[0] != GiteaIsPublic {
+		t.Errorf("VisibilityList() = %v, want only public", vl)
+	}
+
+	// private snippet is refused
+	if _, err := g.Create(ctx, SnippetData{Visibility: GiteaIsPrivate, Files: []SnippetFileData{{Path: "a.sh"}}}); err == nil {
+		t.Error("Create() of private snippet in public repository succeeded")
+	}
+
+	// default is public
+	created, err := g.Create(ctx, SnippetData{Files: []SnippetFileData{{Path: "a.sh"}}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if created.Visibility != GiteaIsPublic {
+		t.Errorf("Create() visibility = %v, want public", created.Visibility)
+	}
+
+	if _, err = g.Update(ctx, created.Id, SnippetData{Visibility: GiteaIsPrivate}); err == nil {
+		t.Error("Update() to private in public repository succeeded")
+	}
+
+	// snippet stored as private (ex: the repository was made public later) is shown as public
+	meta, _ := marshalSnippetMetadata(snippetMetadata{Visibility: GiteaIsPrivate.GetCode()})
+	f.files["old/"+snippetMetadataFile] = meta
+	f.files["old/a.sh"] = []byte("a")
+
+	list, err := g.List(ctx, false, false)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 {
+		t.Errorf("List() without secret = %d snippets, want 2", len(list))
+	}
+}
@@ -5,18 +5,20 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/xanzy/go-gitlab"
 )
 
 type GitlabClient struct {
-	ctx          context.Context
 	client       *gitlab.Client
 	Url          string
 	User         string
@@ -24,10 +26,11 @@ type GitlabClient struct {
 	FilterKey    string
 	Project      *gitlab.Project
 
-	// proxy
-	proxy     string
-	proxyUser string
-	proxyPass string
+	// access token. used to clone the git repository of snippet.
+	token string
+
+	// ssl, proxy and retry
+	httpOption httpOption
 }
 
 var (
@@ -37,38 +40,24 @@ var (
 )
 
 // Init
-func (g *GitlabClient) Init(u, token string) (err error) {
-	// create ctx
-	g.ctx = context.Background()
-
-	transport := &http.Transport{}
-	if g.proxy != "" {
-		proxyUrl, err := url.Parse(g.proxy)
-		if err != nil {
-			return err
-		}
-
-		hdr := make(http.Header)
-		hdr.Add("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(g.proxyUser+":"+g.proxyPass)))
-
-		transport = &http.Transport{
-			Proxy: http.ProxyURL(proxyUrl),
-		}
+func (g *GitlabClient) Init(ctx context.Context, u, token string) (err error) {
+	h, err := newHTTPClient(g.httpOption)
+	if err != nil {
+		return
 	}
 
-	h := &http.Client{Transport: transport}
-
 	// Create Gitlab Client
 	g.client, err = gitlab.NewClient(token, gitlab.WithBaseURL(u), gitlab.WithHTTPClient(h))
 	if err != nil {
 		return
 	}
 
-	// set url
+	// set url and token
 	g.Url = u
+	g.token = token
 
 	// set username
-	user, _, err := g.client.Users.CurrentUser()
+	user, _, err := g.client.Users.CurrentUser(gitlab.WithContext(ctx))
 	if err != nil {
 		return
 	}
@@ -81,7 +70,7 @@ func (g *GitlabClient) Init(u, token string) (err error) {
 }
 
 // List
-func (g *GitlabClient) List(isFile, isSecret bool) (snippetList SnippetList, err error) {
+func (g *GitlabClient) List(ctx context.Context, isFile, isSecret bool) (snippetList SnippetList, err error) {
 	// set ListProjectsOptions pageSize
 	const pageSize = 50
 
@@ -94,7 +83,7 @@ func (g *GitlabClient) List(isFile, isSecret bool) (snippetList SnippetList, err
 
 	for {
 		// get snippetList
-		snippetDataList, resp, ferr := g.client.Snippets.ListSnippets(&opt)
+		snippetDataList, resp, ferr := g.client.Snippets.ListSnippets(&opt, gitlab.WithContext(ctx))
 
 		// check error
 		if ferr != nil {
@@ -102,9 +91,10 @@ func (g *GitlabClient) List(isFile, isSecret bool) (snippetList SnippetList, err
 		}
 
 		for _, snippet := range snippetDataList {
+			// get visibility
+			v := getGitlabVisibilityFromString(snippet.Visibility)
+
 			if !isSecret {
-				//
-				v := getGitlabVisibilityFromString(snippet.Visibility)
 				switch v {
 				case GitlabIsPrivate, GitlabIsInternal:
 					continue
@@ -121,6 +111,11 @@ func (g *GitlabClient) List(isFile, isSecret bool) (snippetList SnippetList, err
 				Title:      title,
 				URL:        snippet.WebURL,
 				Visibility: snippet.Visibility,
+				Secret:     v != GitlabIsPublic,
+			}
+
+			if snippet.UpdatedAt != nil {
+				data.UpdatedAt = *snippet.UpdatedAt
 			}
 
 			// check file flag
@@ -154,76 +149,68 @@ func (g *GitlabClient) List(isFile, isSecret bool) (snippetList SnippetList, err
 }
 
 // Get
-func (g *GitlabClient) Get(id string) (snippet SnippetData, err error) {
+func (g *GitlabClient) Get(ctx context.Context, id string) (snippet SnippetData, err error) {
 	intId, err := strconv.Atoi(id)
 	if err != nil {
 		return
 	}
 
-	sn, _, err := g.client.Snippets.GetSnippet(intId)
+	sn, err := g.getSnippet(ctx, intId)
 	if err != nil {
 		return
 	}
 
-	snippetFiles := sn.Files
-	files := []SnippetFileData{}
-	if len(snippetFiles) > 1 {
-		for _, f := range snippetFiles {
-			ref := "main"
-			contentByte, _, err := g.client.Snippets.SnippetFileContent(intId, ref, f.Path)
-			if err != nil {
-				fmt.Println(err)
-				continue
-			}
+	snippet = createGitlabSnippetData(sn)
 
-			filterVal := sn.WebURL + "/" + f.Path
+	// get contents of files
+	for i, f := range snippet.Files {
+		contentByte, ferr := g.getSnippetContent(ctx, intId, f.Path, len(sn.Files) > 1)
+		if ferr != nil {
+			return snippet, classifyError(ferr)
+		}
 
-			fd := SnippetFileData{
-				Filter:   filterVal,
-				RawURL:   f.RawURL,
-				Path:     f.Path,
-				Contents: contentByte,
-			}
+		snippet.Files[i].Contents = contentByte
+	}
 
-			files = append(files, fd)
-		}
-	} else {
-		contentByte, _, _ := g.client.Snippets.SnippetContent(intId)
-		filterVal := sn.WebURL + "/" + sn.FileName
-		fd := SnippetFileData{
-			Filter:   filterVal,
-			RawURL:   sn.RawURL,
-			Path:     sn.FileName,
-			Contents: contentByte,
+	return
+}
+
+// getSnippetContent gets the contents of the file of the snippet. If isMultiple is false, it gets the contents of the snippet.
+func (g *GitlabClient) getSnippetContent(ctx context.Context, intId int, filePath string, isMultiple bool) (contents []byte, err error) {
+	ref := "main"
+
+	if g.Project == nil {
+		if isMultiple {
+			contents, _, err = g.client.Snippets.SnippetFileContent(intId, ref, filePath, gitlab.WithContext(ctx))
+		} else {
+			contents, _, err = g.client.Snippets.SnippetContent(intId, gitlab.WithContext(ctx))
 		}
 
-		files = append(files, fd)
+		return
 	}
 
-	var visibility Visibility
-	switch sn.Visibility {
-	case "private":
-		visibility = GitlabIsPrivate
-	case "public":
-		visibility = GitlabIsPublic
-	case "internal":
-		visibility = GitlabIsInternal
-	default:
+	if !isMultiple {
+		contents, _, err = g.client.ProjectSnippets.SnippetContent(g.Project.ID, intId, gitlab.WithContext(ctx))
+		return
 	}
 
-	snippet = SnippetData{
-		Title:       sn.Title,
-		Description: sn.Description,
-		URL:         sn.WebURL,
-		Visibility:  visibility,
-		Files:       files,
+	// go-gitlab has no method of the raw file of project snippet
+	u := fmt.Sprintf("projects/%d/snippets/%d/files/%s/%s/raw", g.Project.ID, intId, url.PathEscape(ref), url.PathEscape(filePath))
+	req, err := g.client.NewRequest(http.MethodGet, u, nil, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return
 	}
 
-	return
+	var buf bytes.Buffer
+	if _, err = g.client.Do(req, &buf); err != nil {
+		return
+	}
+
+	return buf.Bytes(), nil
 }
 
 // Create
-func (g *GitlabClient) Create(data SnippetData) (snippet SnippetClient, err error) {
+func (g *GitlabClient) Create(ctx context.Context, data SnippetData) (snippet SnippetData, err error) {
 	// set default visiblity
 	if data.Visibility == (Visibility{}) {
 		data.Visibility = GitlabIsPrivate
@@ -235,6 +222,7 @@ func (g *GitlabClient) Create(data SnippetData) (snippet SnippetClient, err erro
 	// set visibility
 	visibility := getGitlabVisibility(data.Visibility)
 
+	var sn *gitlab.Snippet
 	if g.Project == nil {
 		// create opt
 		opt := &gitlab.CreateSnippetOptions{}
@@ -249,7 +237,7 @@ func (g *GitlabClient) Create(data SnippetData) (snippet SnippetClient, err erro
 			opt.Content = &contents
 		}
 
-		snippet, _, err = g.client.Snippets.CreateSnippet(opt)
+		sn, _, err = g.client.Snippets.CreateSnippet(opt, gitlab.WithContext(ctx))
 
 	} else {
 		// create opt
@@ -265,26 +253,36 @@ func (g *GitlabClient) Create(data SnippetData) (snippet SnippetClient, err erro
 			opt.Content = &contents
 		}
 
-		snippet, _, err = g.client.ProjectSnippets.CreateSnippet(g.Project.ID, opt)
+		sn, _, err = g.client.ProjectSnippets.CreateSnippet(g.Project.ID, opt, gitlab.WithContext(ctx))
+	}
+	if err != nil {
+		return
 	}
 
-	return
+	return createGitlabSnippetData(sn), nil
 }
 
 // Update
-func (g *GitlabClient) Update(id string, data SnippetData) (snippet SnippetClient, err error) {
+func (g *GitlabClient) Update(ctx context.Context, id string, data SnippetData) (snippet SnippetData, err error) {
 	intId, err := strconv.Atoi(id)
 	if err != nil {
 		return
 	}
 
+	// get current snippet files. used to decide the action of each file.
+	current, err := g.getSnippet(ctx, intId)
+	if err != nil {
+		return
+	}
+
 	// create files
-	files, fileName, contents := createGitlabUpdateSnippetFiles(data.Files)
+	files, fileName, contents := createGitlabUpdateSnippetFiles(data.Files, getGitlabSnippetPaths(current))
 
 	// set visibility
 	visibility := getGitlabVisibility(data.Visibility)
 
 	// TODO: filesがstructにないっぽいので、PR出す
+	var sn *gitlab.Snippet
 	if g.Project == nil {
 		// create createOpt
 		opt := &gitlab.UpdateSnippetOptions{}
@@ -292,51 +290,292 @@ func (g *GitlabClient) Update(id string, data SnippetData) (snippet SnippetClien
 		opt.Description = gitlab.String(data.Description)
 		opt.Visibility = &visibility
 
-		if len(files) > 1 {
+		if len(files) > 1 || hasGitlabMoveOrDeleteAction(files) {
 			opt.Files = &files
 		} else {
 			opt.FileName = &fileName
 			opt.Content = &contents
 		}
 
-		snippet, _, err = g.client.Snippets.UpdateSnippet(intId, opt)
+		sn, _, err = g.client.Snippets.UpdateSnippet(intId, opt, gitlab.WithContext(ctx))
 	} else {
 		opt := &gitlab.UpdateProjectSnippetOptions{}
 		opt.Title = gitlab.String(data.Title)
 		opt.Description = gitlab.String(data.Description)
 		opt.Visibility = &visibility
 
-		if len(files) > 1 {
+		if len(files) > 1 || hasGitlabMoveOrDeleteAction(files) {
 			opt.Files = &files
 		} else {
 			opt.FileName = &fileName
 			opt.Content = &contents
 		}
 
-		snippet, _, err = g.client.ProjectSnippets.UpdateSnippet(g.Project.ID, intId, opt)
+		sn, _, err = g.client.ProjectSnippets.UpdateSnippet(g.Project.ID, intId, opt, gitlab.WithContext(ctx))
+	}
+	if err != nil {
+		return
 	}
 
-	return
+	return createGitlabSnippetData(sn), nil
 }
 
 // Delete
-func (g *GitlabClient) Delete(strId string) (err error) {
+func (g *GitlabClient) Delete(ctx context.Context, strId string) (err error) {
 	id, err := strconv.Atoi(strId)
 	if err != nil {
 		return
 	}
 
-	_, err = g.client.Snippets.DeleteSnippet(id)
+	if g.Project != nil {
+		_, err = g.client.ProjectSnippets.DeleteSnippet(g.Project.ID, id, gitlab.WithContext(ctx))
+	} else {
+		_, err = g.client.Snippets.DeleteSnippet(id, gitlab.WithContext(ctx))
+	}
+
+	return
+}
+
+// ListComments
+func (g *GitlabClient) ListComments(ctx context.Context, id string) (comments []SnippetComment, err error) {
+	intId, pid, err := g.getSnippetProject(ctx, id)
+	if err != nil {
+		return
+	}
+
+	// set ListSnippetNotesOptions pageSize
+	const pageSize = 50
+
+	opt := &gitlab.ListSnippetNotesOptions{
+		ListOptions: gitlab.ListOptions{
+			Page:    0,
+			PerPage: pageSize,
+		},
+	}
+
+	for {
+		notes, resp, ferr := g.client.Notes.ListSnippetNotes(pid, intId, opt, gitlab.WithContext(ctx))
+		if ferr != nil {
+			return comments, ferr
+		}
+
+		for _, n := range notes {
+			// skip system notes
+			if n.System {
+				continue
+			}
+
+			comments = append(comments, createGitlabSnippetComment(n))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opt.Page = resp.NextPage
+	}
+
+	return
+}
+
+// AddComment
+func (g *GitlabClient) AddComment(ctx context.Context, id, body string) (comment SnippetComment, err error) {
+	intId, pid, err := g.getSnippetProject(ctx, id)
+	if err != nil {
+		return
+	}
+
+	opt := &gitlab.CreateSnippetNoteOptions{Body: gitlab.String(body)}
+	n, _, err := g.client.Notes.CreateSnippetNote(pid, intId, opt, gitlab.WithContext(ctx))
+	if err != nil {
+		return
+	}
+
+	return createGitlabSnippetComment(n), nil
+}
+
+// EditComment
+func (g *GitlabClient) EditComment(ctx context.Context, id, commentId, body string) (comment SnippetComment, err error) {
+	intId, pid, err := g.getSnippetProject(ctx, id)
+	if err != nil {
+		return
+	}
+
+	intCommentId, err := strconv.Atoi(commentId)
+	if err != nil {
+		return
+	}
+
+	opt := &gitlab.UpdateSnippetNoteOptions{Body: gitlab.String(body)}
+	n, _, err := g.client.Notes.UpdateSnippetNote(pid, intId, intCommentId, opt, gitlab.WithContext(ctx))
+	if err != nil {
+		return
+	}
+
+	return createGitlabSnippetComment(n), nil
+}
+
+// DeleteComment
+func (g *GitlabClient) DeleteComment(ctx context.Context, id, commentId string) (err error) {
+	intId, pid, err := g.getSnippetProject(ctx, id)
+	if err != nil {
+		return
+	}
+
+	intCommentId, err := strconv.Atoi(commentId)
+	if err != nil {
+		return
+	}
+
+	_, err = g.client.Notes.DeleteSnippetNote(pid, intId, intCommentId, gitlab.WithContext(ctx))
+
+	return
+}
+
+// getSnippet gets the snippet from the project snippet endpoint if Project is set, otherwise from the personal snippet endpoint.
+func (g *GitlabClient) getSnippet(ctx context.Context, intId int) (sn *gitlab.Snippet, err error) {
+	if g.Project != nil {
+		sn, _, err = g.client.ProjectSnippets.GetSnippet(g.Project.ID, intId, gitlab.WithContext(ctx))
+	} else {
+		sn, _, err = g.client.Snippets.GetSnippet(intId, gitlab.WithContext(ctx))
+	}
+
+	return
+}
+
+// getSnippetProject returns the project of project snippet.
+// Gitlab can only handle notes of project snippets, so personal snippets return ErrCommentNotSupported.
+func (g *GitlabClient) getSnippetProject(ctx context.Context, id string) (intId int, pid interface{}, err error) {
+	intId, err = strconv.Atoi(id)
+	if err != nil {
+		return
+	}
+
+	if g.Project != nil {
+		return intId, g.Project.ID, nil
+	}
+
+	sn, err := g.getSnippet(ctx, intId)
+	if err != nil {
+		return
+	}
+
+	// project snippet url is `<root>/<namespace>/<project>/-/snippets/<id>`
+	u, err := url.Parse(sn.WebURL)
+	if err != nil {
+		return
+	}
+
+	root := strings.TrimSuffix(g.client.BaseURL().Path, "api/v4/")
+	p := strings.TrimPrefix(u.Path, root)
+
+	i := strings.Index(p, "/-/snippets/")
+	if i <= 0 {
+		err = fmt.Errorf("%w: %s is personal snippet", ErrCommentNotSupported, sn.WebURL)
+		return
+	}
+
+	return intId, p[:i], nil
+}
+
+// ListRevisions
+func (g *GitlabClient) ListRevisions(ctx context.Context, id string) (revisions []SnippetRevision, err error) {
+	_, dir, env, err := g.cloneSnippetRepo(ctx, id)
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	return gitLog(ctx, dir, env)
+}
+
+// GetRevision
+// The revision is resolved in the same clone, so that the repository is cloned only once.
+func (g *GitlabClient) GetRevision(ctx context.Context, id, revision string) (snippet SnippetData, err error) {
+	sn, dir, env, err := g.cloneSnippetRepo(ctx, id)
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	revisions, err := gitLog(ctx, dir, env)
+	if err != nil {
+		return
+	}
+
+	revision, err = resolveRevision(revisions, revision)
+	if err != nil {
+		return
+	}
+
+	files, err := gitReadTree(ctx, dir, env, revision, "")
+	if err != nil {
+		return
+	}
+
+	// title, description and visibility are not in the repository. use the current values.
+	snippet = createGitlabSnippetData(sn)
+	for i := range files {
+		files[i].Filter = sn.WebURL + "/" + files[i].Path
+	}
+	snippet.Files = files
+
 	return
 }
 
+// cloneSnippetRepo gets the snippet and clones its git repository. The caller removes dir.
+// env is the environment variables of git to access the repository.
+func (g *GitlabClient) cloneSnippetRepo(ctx context.Context, id string) (sn *gitlab.Snippet, dir string, env []string, err error) {
+	// project snippet is got from the project endpoint
+	path := "snippets/" + url.PathEscape(id)
+	if g.Project != nil {
+		path = fmt.Sprintf("projects/%d/snippets/%s", g.Project.ID, url.PathEscape(id))
+	}
+
+	// go-gitlab's Snippet has no http_url_to_repo
+	req, err := g.client.NewRequest(http.MethodGet, path, nil, []gitlab.RequestOptionFunc{gitlab.WithContext(ctx)})
+	if err != nil {
+		return
+	}
+
+	var repoSnippet struct {
+		gitlab.Snippet
+		HTTPURLToRepo string `json:"http_url_to_repo"`
+	}
+	if _, err = g.client.Do(req, &repoSnippet); err != nil {
+		return
+	}
+	sn = &repoSnippet.Snippet
+
+	if repoSnippet.HTTPURLToRepo == "" {
+		return sn, "", nil, fmt.Errorf("%w: snippet %s has no repository", ErrHistoryNotSupported, id)
+	}
+
+	auth := base64.StdEncoding.EncodeToString([]byte("oauth2:" + g.token))
+	env = newGitEnv(g.httpOption, "Authorization: Basic "+auth)
+
+	dir, err = cloneGitRepo(ctx, repoSnippet.HTTPURLToRepo, env)
+
+	return
+}
+
+// ListTokenScopes
+func (g *GitlabClient) ListTokenScopes(ctx context.Context) (scopes []string, err error) {
+	token, _, err := g.client.PersonalAccessTokens.GetSinglePersonalAccessToken(gitlab.WithContext(ctx))
+	if err != nil {
+		return
+	}
+
+	return token.Scopes, nil
+}
+
 // GetPlatformName
 func (g *GitlabClient) GetPlatformName() string {
 	return g.PlatformName
 }
 
 // GetProjectList
-func (g *GitlabClient) GetProjectList() (projectList []*gitlab.Project, err error) {
+func (g *GitlabClient) GetProjectList(ctx context.Context) (projectList []*gitlab.Project, err error) {
 	// set ListProjectsOptions pageSize
 	const pageSize = 50
 
@@ -350,7 +589,7 @@ func (g *GitlabClient) GetProjectList() (projectList []*gitlab.Project, err erro
 	}
 
 	for {
-		projects, resp, err := g.client.Projects.ListProjects(&opt)
+		projects, resp, err := g.client.Projects.ListProjects(&opt, gitlab.WithContext(ctx))
 		if err != nil {
 			return []*gitlab.Project{}, err
 		}
@@ -420,20 +659,46 @@ func createGitlabCreateSnippetFiles(data []SnippetFileData) (files []*gitlab.Cre
 }
 
 // createGistFile
-func createGitlabUpdateSnippetFiles(data []SnippetFileData) (files []*gitlab.UpdateSnippetFileOptions, fileName, contents string) {
+func createGitlabUpdateSnippetFiles(data []SnippetFileData, currentPaths []string) (files []*gitlab.UpdateSnippetFileOptions, fileName, contents string) {
 	// set data to files
 	i := 0
 	for _, d := range data {
+		// deleted file
+		if d.Deleted {
+			filepath := d.Path
+			files = append(files, &gitlab.UpdateSnippetFileOptions{
+				Action:   gitlab.String("delete"),
+				FilePath: &filepath,
+			})
+			continue
+		}
+
 		// []byte to string
 		c := string(d.Contents)
 		filepath := d.Path
 
+		// files that do not exist in the remote snippet yet are created.
+		action := "create"
+		for _, p := range currentPaths {
+			if p == d.Path {
+				action = "update"
+				break
+			}
+		}
+
 		f := gitlab.UpdateSnippetFileOptions{
-			Action:   gitlab.String("update"),
+			Action:   gitlab.String(action),
 			FilePath: &filepath,
 			Content:  &c,
 		}
 
+		// renamed file
+		if d.PreviousPath != "" && d.PreviousPath != d.Path {
+			previousPath := d.PreviousPath
+			f.Action = gitlab.String("move")
+			f.PreviousPath = &previousPath
+		}
+
 		files = append(files, &f)
 
 		if i == 0 {
@@ -447,6 +712,84 @@ func createGitlabUpdateSnippetFiles(data []SnippetFileData) (files []*gitlab.Upd
 	return
 }
 
+// hasGitlabMoveOrDeleteAction returns true if files have the action that needs the files parameter.
+func hasGitlabMoveOrDeleteAction(files []*gitlab.UpdateSnippetFileOptions) bool {
+	for _, f := range files {
+		if f.Action != nil && (*f.Action == "move" || *f.Action == "delete") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getGitlabSnippetPaths
+func getGitlabSnippetPaths(sn *gitlab.Snippet) (paths []string) {
+	if len(sn.Files) == 0 {
+		return []string{sn.FileName}
+	}
+
+	for _, f := range sn.Files {
+		paths = append(paths, f.Path)
+	}
+
+	return
+}
+
+// createGitlabSnippetComment
+func createGitlabSnippetComment(n *gitlab.Note) (comment SnippetComment) {
+	comment = SnippetComment{
+		Id:     strconv.Itoa(n.ID),
+		Author: n.Author.Username,
+		Body:   n.Body,
+	}
+
+	if n.CreatedAt != nil {
+		comment.CreatedAt = *n.CreatedAt
+	}
+
+	return
+}
+
+// createGitlabSnippetData creates SnippetData without the contents of files.
+func createGitlabSnippetData(sn *gitlab.Snippet) (snippet SnippetData) {
+	files := []SnippetFileData{}
+	if len(sn.Files) > 1 {
+		for _, f := range sn.Files {
+			files = append(files, SnippetFileData{
+				Filter: sn.WebURL + "/" + f.Path,
+				RawURL: f.RawURL,
+				Path:   f.Path,
+			})
+		}
+	} else {
+		files = append(files, SnippetFileData{
+			Filter: sn.WebURL + "/" + sn.FileName,
+			RawURL: sn.RawURL,
+			Path:   sn.FileName,
+		})
+	}
+
+	snippet = SnippetData{
+		Id:          strconv.Itoa(sn.ID),
+		Title:       sn.Title,
+		Description: sn.Description,
+		URL:         sn.WebURL,
+		Visibility:  getGitlabVisibilityFromString(sn.Visibility),
+		Files:       files,
+	}
+
+	if sn.CreatedAt != nil {
+		snippet.CreatedAt = *sn.CreatedAt
+	}
+
+	if sn.UpdatedAt != nil {
+		snippet.UpdatedAt = *sn.UpdatedAt
+	}
+
+	return
+}
+
 // getGitlabVisibility
 func getGitlabVisibility(v Visibility) (visibility gitlab.VisibilityValue) {
 	switch v {
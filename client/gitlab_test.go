@@ -0,0 +1,221 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// newTestSnippetRepo creates the bare repository root/snippet.git with a commit of each contents of a.sh, newest last.
+func newTestSnippetRepo(t *testing.T, root string, contents ...string) {
+	ctx := context.Background()
+	work := t.TempDir()
+	identity := []string{"-c", "user.name=author", "-c", "user.email=author@localhost"}
+
+	if _, err := runGit(ctx, work, nil, "init", "-q"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range contents {
+		if err := os.WriteFile(filepath.Join(work, "a.sh"), []byte(c), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		args := append(identity, "commit", "-q", "-m", c)
+		if _, err := runGit(ctx, work, nil, "add", "a.sh"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := runGit(ctx, work, nil, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := runGit(ctx, "", nil, "clone", "--bare", "-q", work, filepath.Join(root, "snippet.git")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newFakeGitlab starts the stand-in of GitLab REST API. The handlers of snippets are added to mux by the caller.
+// The endpoints not added return 404, so the personal endpoints of project snippets are not found.
+func newFakeGitlab(t *testing.T) (mux *http.ServeMux, srv *httptest.Server) {
+	mux = http.NewServeMux()
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"id": 1, "username": "user", "name": "user"})
+	})
+
+	return
+}
+
+// newTestGitlabClient returns the client of the project snippets of projectId.
+func newTestGitlabClient(t *testing.T, srv *httptest.Server, projectId int) *GitlabClient {
+	g := &GitlabClient{}
+	if err := g.Init(context.Background(), srv.URL+"/api/v4", "secret"); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	g.Project = &gitlab.Project{ID: projectId}
+
+	return g
+}
+
+func TestGitlabClientProjectSnippet(t *testing.T) {
+	ctx := context.Background()
+	mux, srv := newFakeGitlab(t)
+
+	deleted := map[string]bool{}
+	var updateOpt gitlab.UpdateProjectSnippetOptions
+	mux.HandleFunc("/api/v4/projects/1/snippets/", func(w http.ResponseWriter, r *http.Request) {
+		p := strings.TrimPrefix(r.URL.Path, "/api/v4/projects/1/snippets/")
+		switch {
+		case r.Method == http.MethodDelete:
+			deleted[p] = true
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPut:
+			json.NewDecoder(r.Body).Decode(&updateOpt)
+			writeJSON(w, map[string]interface{}{"id": 5, "web_url": srv.URL + "/group/project/-/snippets/5"})
+		case p == "5":
+			writeJSON(w, map[string]interface{}{
+				"id":      5,
+				"title":   "multiple",
+				"web_url": srv.URL + "/group/project/-/snippets/5",
+				"files":   []map[string]string{{"path": "a.sh"}, {"path": "dir/b.sh"}},
+			})
+		case p == "6":
+			writeJSON(w, map[string]interface{}{
+				"id":        6,
+				"title":     "single",
+				"file_name": "c.sh",
+				"web_url":   srv.URL + "/group/project/-/snippets/6",
+			})
+		case p == "5/files/main/a.sh/raw", p == "5/files/main/dir/b.sh/raw":
+			w.Write([]byte("echo " + p))
+		case p == "6/raw":
+			w.Write([]byte("echo c"))
+		default:
+			http.Error(w, `{"message":"404 Not found"}`, http.StatusNotFound)
+		}
+	})
+
+	g := newTestGitlabClient(t, srv, 1)
+
+	snippet, err := g.Get(ctx, "5")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if len(snippet.Files) != 2 || string(snippet.Files[1].Contents) != "echo 5/files/main/dir/b.sh/raw" {
+		t.Errorf("Get() of multiple files = %+v", snippet)
+	}
+
+	if snippet, err = g.Get(ctx, "6"); err != nil || len(snippet.Files) != 1 || string(snippet.Files[0].Contents) != "echo c" {
+		t.Errorf("Get() of single file = %+v, %v", snippet, err)
+	}
+
+	// update with deleted file
+	_, err = g.Update(ctx, "5", SnippetData{Files: []SnippetFileData{
+		{Path: "a.sh", Contents: []byte("echo a")},
+		{Path: "dir/b.sh", Deleted: true},
+	}})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	var actions []string
+	if updateOpt.Files != nil {
+		for _, f := range *updateOpt.Files {
+			actions = append(actions, gitlab.Stringify(f.Action)+":"+gitlab.Stringify(f.FilePath))
+		}
+	}
+	if got := strings.Join(actions, ","); got != `"update":"a.sh","delete":"dir/b.sh"` {
+		t.Errorf("Update() file actions = %s", got)
+	}
+
+	if err = g.Delete(ctx, "5"); err != nil || !deleted["5"] {
+		t.Errorf("Delete() error = %v, deleted = %v", err, deleted)
+	}
+
+	if _, err = g.Get(ctx, "7"); !errors.Is(classifyError(err), ErrNotFound) {
+		t.Errorf("Get() of unknown snippet error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGitlabClientProjectRevision(t *testing.T) {
+	isolateGitConfig(t)
+
+	ctx := context.Background()
+	root := t.TempDir()
+	newTestSnippetRepo(t, root, "echo old\n", "echo new\n")
+
+	gitPath, _ := exec.LookPath("git")
+	backend := &cgi.Handler{
+		Path: gitPath,
+		Root: "/git",
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+	}
+
+	var clones int32
+	mux, srv := newFakeGitlab(t)
+	mux.HandleFunc("/api/v4/projects/1/snippets/5", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{
+			"id":               5,
+			"title":            "title",
+			"visibility":       "private",
+			"web_url":          srv.URL + "/group/project/-/snippets/5",
+			"http_url_to_repo": srv.URL + "/git/snippet.git",
+		})
+	})
+	mux.HandleFunc("/git/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/info/refs") {
+			atomic.AddInt32(&clones, 1)
+		}
+		backend.ServeHTTP(w, r)
+	})
+
+	g := newTestGitlabClient(t, srv, 1)
+
+	// the personal endpoint /snippets/5 returns 404
+	revisions, err := g.ListRevisions(ctx, "5")
+	if err != nil {
+		t.Fatalf("ListRevisions() error = %v", err)
+	}
+	if len(revisions) != 2 || revisions[1].Message != "echo old" || revisions[1].Author != "author" {
+		t.Fatalf("ListRevisions() = %+v", revisions)
+	}
+
+	// the prefix of revision is resolved in a single clone
+	atomic.StoreInt32(&clones, 0)
+	snippet, err := g.GetRevision(ctx, "5", revisions[1].Revision[:8])
+	if err != nil {
+		t.Fatalf("GetRevision() error = %v", err)
+	}
+	if n := atomic.LoadInt32(&clones); n != 1 {
+		t.Errorf("GetRevision() cloned %d times, want 1", n)
+	}
+	if snippet.Title != "title" || len(snippet.Files) != 1 || string(snippet.Files[0].Contents) != "echo old\n" {
+		t.Errorf("GetRevision() = %+v", snippet)
+	}
+	if want := srv.URL + "/group/project/-/snippets/5/a.sh"; snippet.Files[0].Filter != want {
+		t.Errorf("GetRevision() filter = %s, want %s", snippet.Files[0].Filter, want)
+	}
+
+	if _, err = g.GetRevision(ctx, "5", "ffffffff"); err == nil {
+		t.Error("GetRevision() of unknown revision succeeded")
+	}
+}
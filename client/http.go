@@ -0,0 +1,240 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/blacknon/snipt/config"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// HTTPError is the error response of the REST API used by backends without SDK.
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Message    string
+}
+
+// Error
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, e.Message)
+}
+
+// httpOption is the option of http.Client of the account.
+type httpOption struct {
+	config.HTTPConfig
+
+	// account is the name of account, used in the verbose log.
+	account string
+
+	// verbose is the writer of verbose log. If nil, it is not logged.
+	verbose io.Writer
+}
+
+// newHTTPClient creates http.Client for the backend from httpOption.
+// This is shared by all backends, so ssl, proxy and retry settings work in the same way.
+func newHTTPClient(opt httpOption) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(opt.HTTPConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	proxy, err := newProxyFunc(opt.HTTPConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// keep the timeouts and HTTP/2 of the default transport
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = proxy
+
+	maxAttempts := opt.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	rt := &retryTransport{
+		base:        transport,
+		maxAttempts: maxAttempts,
+		account:     opt.account,
+		verbose:     opt.verbose,
+	}
+
+	return &http.Client{Transport: rt}, nil
+}
+
+// newProxyFunc creates the proxy function of http.Transport.
+// `proxy` is used for both http and https. If it is empty, HTTPS_PROXY/HTTP_PROXY (or ALL_PROXY) environment variables are used.
+// The hosts in `no_proxy` (or NO_PROXY environment variable) are connected directly.
+// `proxy_user`/`proxy_pass` are set to the proxy url, and it is sent as Proxy-Authorization (http/https) or SOCKS5 authentication.
+func newProxyFunc(conf config.HTTPConfig) (func(*http.Request) (*url.URL, error), error) {
+	pc := httpproxy.FromEnvironment()
+
+	// ALL_PROXY is used when HTTP_PROXY and HTTPS_PROXY are not set.
+	if pc.HTTPProxy == "" && pc.HTTPSProxy == "" {
+		allProxy := os.Getenv("ALL_PROXY")
+		if allProxy == "" {
+			allProxy = os.Getenv("all_proxy")
+		}
+
+		pc.HTTPProxy, pc.HTTPSProxy = allProxy, allProxy
+	}
+
+	if conf.Proxy != "" {
+		proxyUrl, err := parseProxyURL(conf.Proxy, conf.ProxyUser, conf.ProxyPass)
+		if err != nil {
+			return nil, err
+		}
+
+		pc.HTTPProxy, pc.HTTPSProxy = proxyUrl.String(), proxyUrl.String()
+	}
+
+	if conf.NoProxy != "" {
+		pc.NoProxy = conf.NoProxy
+	}
+
+	proxyFunc := pc.ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		u, err := proxyFunc(req.URL)
+		if err != nil || u == nil {
+			return u, err
+		}
+
+		// net/http resolves the host name at the SOCKS5 proxy with `socks5` scheme.
+		if u.Scheme == "socks5h" {
+			pu := *u
+			pu.Scheme = "socks5"
+			u = &pu
+		}
+
+		return u, nil
+	}, nil
+}
+
+// parseProxyURL parses the proxy url, and sets the user and password. If the scheme is omitted, http is used.
+func parseProxyURL(proxy, proxyUser, proxyPass string) (proxyUrl *url.URL, err error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+
+	proxyUrl, err = url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy: %v", err)
+	}
+
+	switch proxyUrl.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme: %s", proxyUrl.Scheme)
+	}
+
+	if proxyUser != "" {
+		proxyUrl.User = url.UserPassword(proxyUser, proxyPass)
+	}
+
+	return
+}
+
+// newTLSConfig creates tls.Config with `skip_ssl`, `ca_file` and `client_cert`/`client_key`.
+func newTLSConfig(conf config.HTTPConfig) (tlsConfig *tls.Config, err error) {
+	tlsConfig = &tls.Config{
+		InsecureSkipVerify: conf.Insecure,
+	}
+
+	// add CA certificates to the system pool
+	if conf.CAFile != "" {
+		pem, rerr := os.ReadFile(expandHome(conf.CAFile))
+		if rerr != nil {
+			return nil, fmt.Errorf("cannot read ca_file: %v", rerr)
+		}
+
+		pool, perr := x509.SystemCertPool()
+		if perr != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in ca_file: %s", conf.CAFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	// client certificate for mutual TLS
+	if conf.ClientCert != "" || conf.ClientKey != "" {
+		if conf.ClientCert == "" || conf.ClientKey == "" {
+			return nil, fmt.Errorf("both client_cert and client_key are required")
+		}
+
+		cert, lerr := tls.LoadX509KeyPair(expandHome(conf.ClientCert), expandHome(conf.ClientKey))
+		if lerr != nil {
+			return nil, fmt.Errorf("cannot load client certificate: %v", lerr)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return
+}
+
+// doHTTPRequest sends the request, and decodes the JSON response to out.
+// If the status is not 2xx, *HTTPError is returned.
+func doHTTPRequest(c *http.Client, req *http.Request, out interface{}) (err error) {
+	resp, err := c.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newHTTPError(req, resp)
+	}
+
+	if out == nil {
+		return
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// newHTTPError creates *HTTPError from the error response.
+// The message is got from `message` or `error.message` of JSON body.
+func newHTTPError(req *http.Request, resp *http.Response) *HTTPError {
+	var e struct {
+		Message string `json:"message"`
+		Error   struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	message := http.StatusText(resp.StatusCode)
+	data, _ := io.ReadAll(resp.Body)
+	if json.Unmarshal(data, &e) == nil {
+		if e.Message != "" {
+			message = e.Message
+		} else if e.Error.Message != "" {
+			message = e.Error.Message
+		}
+	}
+
+	return &HTTPError{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Message:    message,
+	}
+}
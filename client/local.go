@@ -0,0 +1,522 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LocalClient is the client of local directory.
+// Each snippet is a directory, which has files and the metadata file (.snipt.json).
+// If Git is true, the directory is a git repository and every change is committed.
+type LocalClient struct {
+	Dir          string
+	Git          bool
+	PlatformName string
+	FilterKey    string
+}
+
+var (
+	LocalIsPrivate = Visibility{code: "private", num: 0}
+	LocalIsPublic  = Visibility{code: "public", num: 1}
+)
+
+// Init
+func (l *LocalClient) Init(ctx context.Context, dir string, git bool) (err error) {
+	l.Dir, err = filepath.Abs(expandHome(dir))
+	if err != nil {
+		return
+	}
+	l.Git = git
+
+	// Create dir
+	if err = os.MkdirAll(l.Dir, 0700); err != nil {
+		return fmt.Errorf("cannot create directory: %v", err)
+	}
+
+	// Create git repository
+	if l.Git {
+		if _, serr := os.Stat(filepath.Join(l.Dir, ".git")); os.IsNotExist(serr) {
+			if err = l.git(ctx, "init", "-q"); err != nil {
+				return
+			}
+		}
+	}
+
+	// Generate PlatformName
+	l.PlatformName = fmt.Sprintf("local:%s", l.Dir)
+
+	return
+}
+
+// List
+func (l *LocalClient) List(ctx context.Context, isFile, isSecret bool) (snippetList SnippetList, err error) {
+	entries, err := os.ReadDir(l.Dir)
+	if err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+
+		id := e.Name()
+		meta, merr := l.readMetadata(id)
+		if os.IsNotExist(merr) {
+			// not snippet directory
+			continue
+		} else if merr != nil {
+			return snippetList, merr
+		}
+
+		if !isSecret && meta.Visibility != LocalIsPublic.GetCode() {
+			continue
+		}
+
+		// get Description
+		title := replaceNewline(meta.Title, "\\n")
+
+		data := SnippetListData{
+			Client:     l,
+			Platform:   l.PlatformName,
+			Id:         id,
+			Title:      title,
+			URL:        l.snippetURL(id),
+			Visibility: meta.Visibility,
+			Secret:     meta.Visibility != LocalIsPublic.GetCode(),
+			UpdatedAt:  meta.UpdatedAt,
+		}
+
+		if isFile {
+			files, ferr := l.listFiles(id)
+			if ferr != nil {
+				return snippetList, ferr
+			}
+
+			for _, f := range files {
+				fd := data
+				fd.URL = l.fileURL(id, f)
+				fd.RawURL = fd.URL
+				snippetList = append(snippetList, &fd)
+			}
+		} else {
+			snippetList = append(snippetList, &data)
+		}
+	}
+
+	return
+}
+
+// Get
+func (l *LocalClient) Get(ctx context.Context, id string) (snippet SnippetData, err error) {
+	meta, err := l.readMetadata(id)
+	if err != nil {
+		return
+	}
+
+	paths, err := l.listFiles(id)
+	if err != nil {
+		return
+	}
+
+	snippet = l.createSnippetData(id, meta, paths)
+
+	// get contents of files
+	for i, p := range paths {
+		contents, rerr := os.ReadFile(filepath.Join(l.Dir, id, filepath.FromSlash(p)))
+		if rerr != nil {
+			return snippet, rerr
+		}
+
+		snippet.Files[i].Contents = contents
+	}
+
+	return
+}
+
+// Create
+func (l *LocalClient) Create(ctx context.Context, data SnippetData) (snippet SnippetData, err error) {
+	// set default visiblity
+	if data.Visibility == (Visibility{}) {
+		data.Visibility = LocalIsPrivate
+	}
+
+	if err = checkLocalFilePaths(data.Files); err != nil {
+		return
+	}
+
+	id := newSnippetId()
+	now := time.Now().UTC()
+
+	if err = os.MkdirAll(filepath.Join(l.Dir, id), 0700); err != nil {
+		return
+	}
+
+	meta := snippetMetadata{
+		Title:       data.Title,
+		Description: data.Description,
+		Visibility:  data.Visibility.GetCode(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err = l.writeSnippet(id, meta, data.Files); err != nil {
+		return
+	}
+
+	if err = l.commit(ctx, id, "create snippet "+id); err != nil {
+		return
+	}
+
+	return l.getSnippetData(id, meta)
+}
+
+// Update
+func (l *LocalClient) Update(ctx context.Context, id string, data SnippetData) (snippet SnippetData, err error) {
+	meta, err := l.readMetadata(id)
+	if err != nil {
+		return
+	}
+
+	if err = checkLocalFilePaths(data.Files); err != nil {
+		return
+	}
+
+	// update metadata
+	meta.Title = data.Title
+	meta.Description = data.Description
+	if data.Visibility != (Visibility{}) {
+		meta.Visibility = data.Visibility.GetCode()
+	}
+	meta.UpdatedAt = time.Now().UTC()
+
+	// remove renamed and deleted files
+	files := []SnippetFileData{}
+	for _, f := range data.Files {
+		var removePath string
+		switch {
+		case f.Deleted:
+			removePath = f.Path
+		case f.PreviousPath != "" && f.PreviousPath != f.Path:
+			removePath = f.PreviousPath
+			files = append(files, f)
+		default:
+			files = append(files, f)
+			continue
+		}
+
+		err = os.Remove(filepath.Join(l.Dir, id, filepath.FromSlash(removePath)))
+		if err != nil && !os.IsNotExist(err) {
+			return
+		}
+	}
+
+	if err = l.writeSnippet(id, meta, files); err != nil {
+		return
+	}
+
+	if err = l.commit(ctx, id, "update snippet "+id); err != nil {
+		return
+	}
+
+	return l.getSnippetData(id, meta)
+}
+
+// Delete
+func (l *LocalClient) Delete(ctx context.Context, id string) (err error) {
+	// check snippet exists
+	if _, err = l.readMetadata(id); err != nil {
+		return
+	}
+
+	if err = os.RemoveAll(filepath.Join(l.Dir, id)); err != nil {
+		return
+	}
+
+	return l.commit(ctx, id, "delete snippet "+id)
+}
+
+// ListRevisions
+func (l *LocalClient) ListRevisions(ctx context.Context, id string) (revisions []SnippetRevision, err error) {
+	if !l.Git {
+		return nil, ErrHistoryNotSupported
+	}
+
+	return gitLog(ctx, l.Dir, nil, id)
+}
+
+// GetRevision
+func (l *LocalClient) GetRevision(ctx context.Context, id, revision string) (snippet SnippetData, err error) {
+	if !l.Git {
+		return snippet, ErrHistoryNotSupported
+	}
+
+	revisions, err := l.ListRevisions(ctx, id)
+	if err != nil {
+		return
+	}
+
+	revision, err = resolveRevision(revisions, revision)
+	if err != nil {
+		return
+	}
+
+	tree, err := gitReadTree(ctx, l.Dir, nil, revision, id)
+	if err != nil {
+		return
+	}
+
+	// separate the metadata
+	var meta snippetMetadata
+	paths := []string{}
+	contents := map[string][]byte{}
+	for _, f := range tree {
+		if f.Path == snippetMetadataFile {
+			if err = json.Unmarshal(f.Contents, &meta); err != nil {
+				return
+			}
+			continue
+		}
+
+		paths = append(paths, f.Path)
+		contents[f.Path] = f.Contents
+	}
+
+	snippet = l.createSnippetData(id, meta, paths)
+	for i, f := range snippet.Files {
+		snippet.Files[i].Contents = contents[f.Path]
+	}
+
+	return
+}
+
+// GetPlatformName
+func (l *LocalClient) GetPlatformName() string {
+	return l.PlatformName
+}
+
+// GetFilterKey
+func (l *LocalClient) GetFilterKey() string {
+	return l.FilterKey
+}
+
+// SetFilterKey
+func (l *LocalClient) SetFilterKey(key string) {
+	l.FilterKey = key
+}
+
+// VisibilityList
+func (l *LocalClient) VisibilityList() (visibilityList []Visibility) {
+	visibilityList = []Visibility{
+		LocalIsPrivate,
+		LocalIsPublic,
+	}
+
+	return
+}
+
+// readMetadata
+func (l *LocalClient) readMetadata(id string) (meta snippetMetadata, err error) {
+	if !filepath.IsLocal(id) || strings.ContainsAny(id, `/\`) {
+		return meta, fmt.Errorf("invalid snippet id: %s", id)
+	}
+
+	data, err := os.ReadFile(filepath.Join(l.Dir, id, snippetMetadataFile))
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(data, &meta)
+
+	return
+}
+
+// listFiles returns the slash separated paths of the snippet files.
+func (l *LocalClient) listFiles(id string) (files []string, err error) {
+	root := filepath.Join(l.Dir, id)
+
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, werr error) error {
+		if werr != nil {
+			return werr
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, rerr := filepath.Rel(root, p)
+		if rerr != nil {
+			return rerr
+		}
+
+		rel = filepath.ToSlash(rel)
+		if rel != snippetMetadataFile {
+			files = append(files, rel)
+		}
+
+		return nil
+	})
+
+	sort.Strings(files)
+
+	return
+}
+
+// writeSnippet writes the metadata and files of the snippet.
+func (l *LocalClient) writeSnippet(id string, meta snippetMetadata, files []SnippetFileData) (err error) {
+	for _, f := range files {
+		p := filepath.Join(l.Dir, id, filepath.FromSlash(f.Path))
+		if err = os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+			return
+		}
+
+		if err = os.WriteFile(p, f.Contents, 0600); err != nil {
+			return
+		}
+	}
+
+	data, err := marshalSnippetMetadata(meta)
+	if err != nil {
+		return
+	}
+
+	return os.WriteFile(filepath.Join(l.Dir, id, snippetMetadataFile), data, 0600)
+}
+
+// commit commits the changes of the snippet directory, when git is enabled.
+// Nothing is committed if the snippet has no changes, or the deleted snippet was not tracked (created before git is enabled).
+func (l *LocalClient) commit(ctx context.Context, id, message string) (err error) {
+	if !l.Git {
+		return
+	}
+
+	// deleted snippet
+	if _, serr := os.Stat(filepath.Join(l.Dir, id)); os.IsNotExist(serr) {
+		tracked, terr := runGit(ctx, l.Dir, nil, "ls-files", "--", id)
+		if terr != nil || len(tracked) == 0 {
+			return terr
+		}
+	}
+
+	if err = l.git(ctx, "add", "-A", "--", id); err != nil {
+		return
+	}
+
+	changes, err := runGit(ctx, l.Dir, nil, "status", "--porcelain", "--", id)
+	if err != nil || len(changes) == 0 {
+		return
+	}
+
+	args := append(l.gitIdentityArgs(ctx), "commit", "-q", "-m", "snipt: "+message, "--", id)
+
+	return l.git(ctx, args...)
+}
+
+// gitIdentityArgs returns the `-c` options of user.name and user.email, which are not configured.
+// Without them, git commit fails on the machine git is not set up.
+func (l *LocalClient) gitIdentityArgs(ctx context.Context) (args []string) {
+	fallbacks := [][2]string{
+		{"user.name", "snipt"},
+		{"user.email", "snipt@localhost"},
+	}
+
+	for _, f := range fallbacks {
+		if _, err := runGit(ctx, l.Dir, nil, "config", "--get", f[0]); err != nil {
+			args = append(args, "-c", f[0]+"="+f[1])
+		}
+	}
+
+	return
+}
+
+// git runs git command in the directory.
+func (l *LocalClient) git(ctx context.Context, args ...string) (err error) {
+	_, err = runGit(ctx, l.Dir, nil, args...)
+	return
+}
+
+// snippetURL
+func (l *LocalClient) snippetURL(id string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(l.Dir, id))}
+	return u.String()
+}
+
+// fileURL
+func (l *LocalClient) fileURL(id, p string) string {
+	u, _ := url.JoinPath(l.snippetURL(id), p)
+	return u
+}
+
+// getSnippetData gets SnippetData without the contents of files.
+func (l *LocalClient) getSnippetData(id string, meta snippetMetadata) (snippet SnippetData, err error) {
+	paths, err := l.listFiles(id)
+	if err != nil {
+		return
+	}
+
+	return l.createSnippetData(id, meta, paths), nil
+}
+
+// createSnippetData creates SnippetData without the contents of files.
+func (l *LocalClient) createSnippetData(id string, meta snippetMetadata, paths []string) SnippetData {
+	files := []SnippetFileData{}
+	for _, p := range paths {
+		files = append(files, SnippetFileData{
+			Filter: l.fileURL(id, p),
+			RawURL: l.fileURL(id, p),
+			Path:   p,
+		})
+	}
+
+	return SnippetData{
+		Id:          id,
+		Title:       meta.Title,
+		Description: meta.Description,
+		URL:         l.snippetURL(id),
+		Visibility:  getLocalVisibilityFromString(meta.Visibility),
+		Files:       files,
+		CreatedAt:   meta.CreatedAt,
+		UpdatedAt:   meta.UpdatedAt,
+	}
+}
+
+// checkLocalFilePaths checks that the files are written in the snippet directory.
+func checkLocalFilePaths(files []SnippetFileData) error {
+	for _, f := range files {
+		for _, p := range []string{f.Path, f.PreviousPath} {
+			if p == "" {
+				continue
+			}
+
+			if !filepath.IsLocal(filepath.FromSlash(p)) || p == snippetMetadataFile {
+				return fmt.Errorf("invalid file name: %s", p)
+			}
+		}
+	}
+
+	return nil
+}
+
+// getLocalVisibilityFromString
+func getLocalVisibilityFromString(s string) (v Visibility) {
+	switch s {
+	case LocalIsPublic.GetCode():
+		v = LocalIsPublic
+	default:
+		v = LocalIsPrivate
+	}
+
+	return
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestLocalClient
+func newTestLocalClient(t *testing.T, dir string, git bool) *LocalClient {
+	l := &LocalClient{}
+	if err := l.Init(context.Background(), dir, git); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	return l
+}
+
+// isolateGitConfig ignores the git config of the user, so that user.name and user.email are not set.
+func isolateGitConfig(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+}
+
+// testLocalClientFlow creates, updates and deletes a snippet.
+func testLocalClientFlow(t *testing.T, l *LocalClient) (id string) {
+	ctx := context.Background()
+
+	// create
+	created, err := l.Create(ctx, SnippetData{
+		Title: "title",
+		Files: []SnippetFileData{
+			{Path: "a.sh", Contents: []byte("echo a\n")},
+			{Path: "dir/b.sh", Contents: []byte("echo b\n")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	id = created.Id
+
+	if created.Visibility != LocalIsPrivate || len(created.Files) != 2 || created.URL != l.snippetURL(id) {
+		t.Errorf("Create() = %+v", created)
+	}
+
+	// list
+	list, err := l.List(ctx, false, true)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].Id != id || !list[0].Secret {
+		t.Fatalf("List() = %+v", list)
+	}
+
+	if list, _ = l.List(ctx, false, false); len(list) != 0 {
+		t.Errorf("List() without secret = %d snippets, want 0", len(list))
+	}
+
+	// update, rename and add file
+	_, err = l.Update(ctx, id, SnippetData{
+		Title:      "new title",
+		Visibility: LocalIsPublic,
+		Files: []SnippetFileData{
+			{Path: "c.sh", PreviousPath: "a.sh", Contents: []byte("echo c\n")},
+			{Path: "d.sh", Contents: []byte("echo d\n")},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	// get
+	snippet, err := l.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	var paths []string
+	for _, f := range snippet.Files {
+		paths = append(paths, f.Path+"="+string(f.Contents))
+	}
+	if got := strings.Join(paths, ","); got != "c.sh=echo c\n,d.sh=echo d\n,dir/b.sh=echo b\n" {
+		t.Errorf("Get() files = %q", got)
+	}
+	if snippet.Title != "new title" || snippet.Visibility != LocalIsPublic {
+		t.Errorf("Get() = %+v", snippet)
+	}
+
+	// delete file
+	if _, err = l.Update(ctx, id, SnippetData{Title: "new title", Files: []SnippetFileData{{Path: "d.sh", Deleted: true}}}); err != nil {
+		t.Fatalf("Update() with deleted file error = %v", err)
+	}
+	if snippet, _ = l.Get(ctx, id); len(snippet.Files) != 2 || snippet.Files[1].Path != "dir/b.sh" {
+		t.Errorf("Get() files after delete = %+v", snippet.Files)
+	}
+
+	// files outside the snippet directory are refused
+	for _, p := range []string{"../x.sh", "/tmp/x.sh", snippetMetadataFile} {
+		if _, err = l.Update(ctx, id, SnippetData{Files: []SnippetFileData{{Path: p}}}); err == nil {
+			t.Errorf("Update() with path %s succeeded", p)
+		}
+	}
+
+	// delete
+	if err = l.Delete(ctx, id); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	if _, err = l.Get(ctx, id); !errors.Is(classifyError(err), ErrNotFound) {
+		t.Errorf("Get() of deleted snippet error = %v, want ErrNotFound", err)
+	}
+
+	return
+}
+
+func TestLocalClient(t *testing.T) {
+	dir := t.TempDir()
+	l := newTestLocalClient(t, dir, false)
+
+	testLocalClientFlow(t, l)
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); !os.IsNotExist(err) {
+		t.Error("git repository is created without git")
+	}
+
+	if _, err := l.ListRevisions(context.Background(), "x"); !errors.Is(err, ErrHistoryNotSupported) {
+		t.Errorf("ListRevisions() without git error = %v, want ErrHistoryNotSupported", err)
+	}
+}
+
+func TestLocalClientGit(t *testing.T) {
+	isolateGitConfig(t)
+
+	ctx := context.Background()
+	l := newTestLocalClient(t, t.TempDir(), true)
+
+	id := testLocalClientFlow(t, l)
+
+	// create, updates and delete are committed with the fallback identity
+	revisions, err := gitLog(ctx, l.Dir, nil, id)
+	if err != nil {
+		t.Fatalf("gitLog() error = %v", err)
+	}
+	if len(revisions) != 4 {
+		t.Fatalf("gitLog() = %d revisions, want 4", len(revisions))
+	}
+	if revisions[0].Author != "snipt" || revisions[0].Message != "snipt: delete snippet "+id {
+		t.Errorf("gitLog()[0] = %+v", revisions[0])
+	}
+
+	// the snippet before delete, by the prefix of revision
+	snippet, err := l.GetRevision(ctx, id, revisions[1].Revision[:8])
+	if err != nil {
+		t.Fatalf("GetRevision() error = %v", err)
+	}
+	if snippet.Title != "new title" || len(snippet.Files) != 2 {
+		t.Errorf("GetRevision() = %+v", snippet)
+	}
+
+	// no changes
+	if err = l.commit(ctx, id, "no changes"); err != nil {
+		t.Errorf("commit() without changes error = %v", err)
+	}
+}
+
+func TestLocalClientGitEnabledLater(t *testing.T) {
+	isolateGitConfig(t)
+
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// snippet created before git is enabled
+	created, err := newTestLocalClient(t, dir, false).Create(ctx, SnippetData{Files: []SnippetFileData{{Path: "a.sh"}}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	l := newTestLocalClient(t, dir, true)
+	if err = l.Delete(ctx, created.Id); err != nil {
+		t.Errorf("Delete() of untracked snippet error = %v", err)
+	}
+
+	// tracked snippet is committed as before
+	created, err = l.Create(ctx, SnippetData{Files: []SnippetFileData{{Path: "b.sh"}}})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	revisions, err := l.ListRevisions(ctx, created.Id)
+	if err != nil || len(revisions) != 1 {
+		t.Errorf("ListRevisions() = %v, %v", revisions, err)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// snippetMetadataFile is the file name of snippet metadata, for the backends that store snippets in a directory.
+const snippetMetadataFile = ".snipt.json"
+
+// snippetMetadata is the metadata of the snippet stored in snippetMetadataFile.
+type snippetMetadata struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Visibility  string    `json:"visibility"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// newSnippetId generates the directory name of new snippet. ex) 20240102150405-1a2b3c
+func newSnippetId() string {
+	b := make([]byte, 3)
+	rand.Read(b)
+
+	return time.Now().UTC().Format("20060102150405") + "-" + hex.EncodeToString(b)
+}
+
+// marshalSnippetMetadata
+func marshalSnippetMetadata(m snippetMetadata) ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultMaxAttempts is the default value of `max_attempts`.
+	defaultMaxAttempts = 3
+
+	// retryBaseWait is the first wait of the exponential backoff.
+	retryBaseWait = time.Second
+
+	// retryMaxWait is the max wait before retry. If the rate limit is reset later than this, it is not retried.
+	retryMaxWait = time.Minute
+)
+
+// retryTransport is the http.RoundTripper that retries the request with exponential backoff and jitter.
+// It retries the rate limited requests (429, or 403 with Retry-After or no remaining quota) waiting until
+// Retry-After/X-RateLimit-Reset, and the network errors and 502/503/504 of the idempotent requests.
+type retryTransport struct {
+	base        http.RoundTripper
+	maxAttempts int
+
+	// verbose log
+	account string
+	verbose io.Writer
+}
+
+// RoundTrip
+func (t *retryTransport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	for attempt := 1; ; attempt++ {
+		resp, err = t.base.RoundTrip(req)
+		t.logResponse(req, resp, err)
+
+		if attempt >= t.maxAttempts || req.Context().Err() != nil {
+			return
+		}
+
+		wait, ok := getRetryWait(req, resp, err, attempt)
+		if !ok {
+			return
+		}
+
+		// the request body can not be sent again
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return
+		}
+
+		t.logf("retry %s %s in %s (attempt %d/%d)", req.Method, req.URL.Redacted(), wait.Round(time.Millisecond), attempt+1, t.maxAttempts)
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		// rewind the request body
+		if req.GetBody != nil {
+			body, berr := req.GetBody()
+			if berr != nil {
+				return nil, berr
+			}
+
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// logResponse logs the response and the rate limit quota.
+func (t *retryTransport) logResponse(req *http.Request, resp *http.Response, err error) {
+	if t.verbose == nil {
+		return
+	}
+
+	if err != nil {
+		t.logf("%s %s: %s", req.Method, req.URL.Redacted(), err)
+		return
+	}
+
+	quota := ""
+	if remaining, limit := getRateLimitHeader(resp.Header, "Remaining"), getRateLimitHeader(resp.Header, "Limit"); remaining != "" {
+		quota = fmt.Sprintf(" (rate limit remaining %s/%s)", remaining, limit)
+	}
+
+	t.logf("%s %s: %d%s", req.Method, req.URL.Redacted(), resp.StatusCode, quota)
+}
+
+// logf
+func (t *retryTransport) logf(format string, a ...interface{}) {
+	if t.verbose == nil {
+		return
+	}
+
+	fmt.Fprintf(t.verbose, "Verbose: %s: %s\n", t.account, fmt.Sprintf(format, a...))
+}
+
+// getRetryWait returns the wait before retry, and whether the request should be retried.
+func getRetryWait(req *http.Request, resp *http.Response, err error, attempt int) (wait time.Duration, ok bool) {
+	idempotent := isIdempotentMethod(req.Method)
+
+	switch {
+	case err != nil:
+		// network error
+		if !idempotent {
+			return
+		}
+
+	case resp.StatusCode == http.StatusTooManyRequests:
+		// rate limited. the request was not processed, so any method can be retried.
+		if wait, ok = getRateLimitWait(resp.Header); ok {
+			return wait, wait <= retryMaxWait
+		}
+
+	case resp.StatusCode == http.StatusForbidden:
+		// secondary rate limit of GitHub, or no remaining quota
+		if resp.Header.Get("Retry-After") == "" && getRateLimitHeader(resp.Header, "Remaining") != "0" {
+			return
+		}
+
+		if wait, ok = getRateLimitWait(resp.Header); ok {
+			return wait, wait <= retryMaxWait
+		}
+
+	case resp.StatusCode == http.StatusBadGateway, resp.StatusCode == http.StatusServiceUnavailable, resp.StatusCode == http.StatusGatewayTimeout:
+		if !idempotent {
+			return
+		}
+
+		if wait, ok = getRateLimitWait(resp.Header); ok {
+			return wait, wait <= retryMaxWait
+		}
+
+	default:
+		return
+	}
+
+	return getBackoffWait(attempt), true
+}
+
+// getRateLimitWait returns the wait from Retry-After or X-RateLimit-Reset header.
+func getRateLimitWait(header http.Header) (wait time.Duration, ok bool) {
+	// Retry-After: seconds or HTTP-date
+	if v := header.Get("Retry-After"); v != "" {
+		if sec, err := strconv.Atoi(v); err == nil {
+			return time.Duration(sec) * time.Second, true
+		}
+
+		if t, err := http.ParseTime(v); err == nil {
+			return max(time.Until(t), 0), true
+		}
+	}
+
+	// X-RateLimit-Reset (GitHub) / RateLimit-Reset (GitLab): unix time
+	if v := getRateLimitHeader(header, "Reset"); v != "" && getRateLimitHeader(header, "Remaining") == "0" {
+		if sec, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return max(time.Until(time.Unix(sec, 0)), 0) + time.Second, true
+		}
+	}
+
+	return
+}
+
+// getRateLimitHeader returns X-RateLimit-<name> or RateLimit-<name> header.
+func getRateLimitHeader(header http.Header, name string) string {
+	if v := header.Get("X-RateLimit-" + name); v != "" {
+		return v
+	}
+
+	return header.Get("RateLimit-" + name)
+}
+
+// getBackoffWait returns the exponential backoff with jitter. ex) attempt 1: 0.5-1s, 2: 1-2s, 3: 2-4s
+func getBackoffWait(attempt int) time.Duration {
+	d := retryBaseWait << (attempt - 1)
+	if d <= 0 || d > retryMaxWait {
+		d = retryMaxWait
+	}
+
+	return d/2 + rand.N(d/2+1)
+}
+
+// isIdempotentMethod
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newTestHeader
+func newTestHeader(kv ...string) http.Header {
+	h := http.Header{}
+	for i := 0; i+1 < len(kv); i += 2 {
+		h.Set(kv[i], kv[i+1])
+	}
+
+	return h
+}
+
+func TestGetRateLimitWait(t *testing.T) {
+	now := time.Now()
+	reset := strconv.FormatInt(now.Add(30*time.Second).Unix(), 10)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		min    time.Duration
+		max    time.Duration
+		wantOk bool
+	}{
+		{"no header", newTestHeader(), 0, 0, false},
+		{"retry-after seconds", newTestHeader("Retry-After", "5"), 5 * time.Second, 5 * time.Second, true},
+		{"retry-after date", newTestHeader("Retry-After", now.Add(10*time.Second).UTC().Format(http.TimeFormat)), 8 * time.Second, 10 * time.Second, true},
+		{"retry-after past date", newTestHeader("Retry-After", now.Add(-time.Hour).UTC().Format(http.TimeFormat)), 0, 0, true},
+		{"retry-after invalid", newTestHeader("Retry-After", "soon"), 0, 0, false},
+		{"github reset", newTestHeader("X-RateLimit-Remaining", "0", "X-RateLimit-Reset", reset), 29 * time.Second, 32 * time.Second, true},
+		{"gitlab reset", newTestHeader("RateLimit-Remaining", "0", "RateLimit-Reset", reset), 29 * time.Second, 32 * time.Second, true},
+		{"reset with remaining quota", newTestHeader("X-RateLimit-Remaining", "10", "X-RateLimit-Reset", reset), 0, 0, false},
+		{"retry-after is preferred", newTestHeader("Retry-After", "3", "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", reset), 3 * time.Second, 3 * time.Second, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wait, ok := getRateLimitWait(tt.header)
+			if ok != tt.wantOk {
+				t.Fatalf("getRateLimitWait() ok = %v, want %v", ok, tt.wantOk)
+			}
+
+			if wait < tt.min || wait > tt.max {
+				t.Errorf("getRateLimitWait() = %v, want %v-%v", wait, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestGetRetryWait(t *testing.T) {
+	errNetwork := errors.New("connection reset")
+
+	tests := []struct {
+		name   string
+		method string
+		status int
+		header http.Header
+		err    error
+		min    time.Duration
+		max    time.Duration
+		wantOk bool
+	}{
+		{"success", http.MethodGet, http.StatusOK, nil, nil, 0, 0, false},
+		{"not found", http.MethodGet, http.StatusNotFound, nil, nil, 0, 0, false},
+		{"network error of get", http.MethodGet, 0, nil, errNetwork, retryBaseWait / 2, retryBaseWait, true},
+		{"network error of post", http.MethodPost, 0, nil, errNetwork, 0, 0, false},
+		{"429 with retry-after", http.MethodPost, http.StatusTooManyRequests, newTestHeader("Retry-After", "2"), nil, 2 * time.Second, 2 * time.Second, true},
+		{"429 without header", http.MethodPost, http.StatusTooManyRequests, nil, nil, retryBaseWait / 2, retryBaseWait, true},
+		{"429 waits too long", http.MethodGet, http.StatusTooManyRequests, newTestHeader("Retry-After", "3600"), nil, time.Hour, time.Hour, false},
+		{"403 forbidden", http.MethodGet, http.StatusForbidden, nil, nil, 0, 0, false},
+		{"403 secondary rate limit", http.MethodGet, http.StatusForbidden, newTestHeader("Retry-After", "1"), nil, time.Second, time.Second, true},
+		{"503 of get", http.MethodGet, http.StatusServiceUnavailable, nil, nil, retryBaseWait / 2, retryBaseWait, true},
+		{"503 of post", http.MethodPost, http.StatusServiceUnavailable, nil, nil, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, "https://example.com/", nil)
+
+			var resp *http.Response
+			if tt.err == nil {
+				header := tt.header
+				if header == nil {
+					header = http.Header{}
+				}
+				resp = &http.Response{StatusCode: tt.status, Header: header}
+			}
+
+			wait, ok := getRetryWait(req, resp, tt.err, 1)
+			if ok != tt.wantOk {
+				t.Fatalf("getRetryWait() ok = %v, want %v", ok, tt.wantOk)
+			}
+
+			if wait < tt.min || wait > tt.max {
+				t.Errorf("getRetryWait() = %v, want %v-%v", wait, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestGetBackoffWait(t *testing.T) {
+	for attempt := 1; attempt <= 10; attempt++ {
+		d := min(retryBaseWait<<(attempt-1), retryMaxWait)
+
+		if wait := getBackoffWait(attempt); wait < d/2 || wait > d {
+			t.Errorf("getBackoffWait(%d) = %v, want %v-%v", attempt, wait, d/2, d)
+		}
+	}
+}
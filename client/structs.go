@@ -4,7 +4,14 @@
 
 package client
 
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
 // GitClient
+// The methods accessing the remote platform take context.Context, to be canceled by timeout or signal.
 type GitClient interface {
 	// Get struct.PlatformName
 	GetPlatformName() string
@@ -16,26 +23,58 @@ type GitClient interface {
 	SetFilterKey(key string)
 
 	// List
-	List(isFile, isSecret bool) (SnippetList, error)
+	List(ctx context.Context, isFile, isSecret bool) (SnippetList, error)
 
 	// Get
-	Get(id string) (SnippetData, error)
+	Get(ctx context.Context, id string) (SnippetData, error)
 
-	// Create
-	Create(data SnippetData) (SnippetClient, error)
+	// Create returns the created snippet. Contents of the files may be empty.
+	Create(ctx context.Context, data SnippetData) (SnippetData, error)
 
-	// Update
-	Update(id string, data SnippetData) (SnippetClient, error)
+	// Update returns the updated snippet. Contents of the files may be empty.
+	Update(ctx context.Context, id string, data SnippetData) (SnippetData, error)
 
 	// Delete
-	Delete(id string) error
+	Delete(ctx context.Context, id string) error
 
 	// VisibilityList
 	VisibilityList() (visibilityList []Visibility)
 }
 
-// Snippet
-type SnippetClient interface{}
+// SnippetSinceLister is implemented by the GitClient that can list only the snippets updated after since.
+// It is used to refresh the cache incrementally.
+type SnippetSinceLister interface {
+	ListSince(ctx context.Context, isFile, isSecret bool, since time.Time) (SnippetList, error)
+}
+
+// TokenScopeLister is implemented by the GitClient that can show the scopes of the access token.
+type TokenScopeLister interface {
+	ListTokenScopes(ctx context.Context) ([]string, error)
+}
+
+// SnippetHistorian is implemented by the GitClient that can get the revisions of snippets.
+type SnippetHistorian interface {
+	// ListRevisions returns the revisions of the snippet, newest first.
+	ListRevisions(ctx context.Context, id string) ([]SnippetRevision, error)
+
+	// GetRevision returns the snippet at the revision. revision can be the prefix of the revision.
+	GetRevision(ctx context.Context, id, revision string) (SnippetData, error)
+}
+
+// SnippetCommenter is implemented by the GitClient that can handle snippet comments.
+type SnippetCommenter interface {
+	// ListComments
+	ListComments(ctx context.Context, id string) ([]SnippetComment, error)
+
+	// AddComment
+	AddComment(ctx context.Context, id, body string) (SnippetComment, error)
+
+	// EditComment
+	EditComment(ctx context.Context, id, commentId, body string) (SnippetComment, error)
+
+	// DeleteComment
+	DeleteComment(ctx context.Context, id, commentId string) error
+}
 
 // SnippetList
 type SnippetList []*SnippetListData
@@ -54,20 +93,26 @@ func (l SnippetList) Where(fn func(*SnippetListData) bool) (result SnippetList)
 // SnippetList
 type SnippetListData struct {
 	Client     GitClient
-	Platform   string // platform the snippet resides on. ex) Github(hogehoge)/Gitlab(fugafuga)
-	Id         string //
-	Title      string //
-	RawURL     string //
-	URL        string //
-	Visibility string //
+	Platform   string    // platform the snippet resides on. ex) Github(hogehoge)/Gitlab(fugafuga)
+	Id         string    //
+	Title      string    //
+	RawURL     string    //
+	URL        string    //
+	Visibility string    //
+	Secret     bool      // true if the snippet is not public
+	UpdatedAt  time.Time //
 }
 
+// SnippetData
 type SnippetData struct {
+	Id          string
 	Title       string
 	Description string
 	URL         string
 	Visibility  Visibility
 	Files       []SnippetFileData
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 func (s *SnippetData) AddFilter(val string) {
@@ -86,6 +131,25 @@ type SnippetFileData struct {
 	Path         string
 	PreviousPath string
 	Contents     []byte
+
+	// Deleted is set to delete the file of Path by Update.
+	Deleted bool
+}
+
+// SnippetComment
+type SnippetComment struct {
+	Id        string
+	Author    string
+	Body      string
+	CreatedAt time.Time
+}
+
+// SnippetRevision
+type SnippetRevision struct {
+	Revision  string
+	Author    string
+	Message   string // commit message, or the changes of gist. ex) +3 -1
+	CreatedAt time.Time
 }
 
 // Visibility
@@ -101,3 +165,52 @@ func (v *Visibility) GetCode() string {
 func (v *Visibility) GetNum() int {
 	return v.num
 }
+
+// visibilityJSON
+type visibilityJSON struct {
+	Code string `json:"code"`
+	Num  int    `json:"num"`
+}
+
+// MarshalJSON
+func (v Visibility) MarshalJSON() ([]byte, error) {
+	return json.Marshal(visibilityJSON{Code: v.code, Num: v.num})
+}
+
+// UnmarshalJSON
+func (v *Visibility) UnmarshalJSON(data []byte) (err error) {
+	var vj visibilityJSON
+	if err = json.Unmarshal(data, &vj); err != nil {
+		return
+	}
+
+	v.code = vj.Code
+	v.num = vj.Num
+
+	return
+}
+
+// NearestVisibility returns the visibility in visibilityList closest to v.
+// The same code is preferred, then the same num, and otherwise the most
+// restricted visibility in the list.
+func NearestVisibility(v Visibility, visibilityList []Visibility) (nearest Visibility) {
+	for _, vl := range visibilityList {
+		if vl.code == v.code {
+			return vl
+		}
+	}
+
+	for _, vl := range visibilityList {
+		if vl.num == v.num {
+			return vl
+		}
+	}
+
+	for i, vl := range visibilityList {
+		if i == 0 || vl.num < nearest.num {
+			nearest = vl
+		}
+	}
+
+	return
+}
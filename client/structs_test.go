@@ -0,0 +1,52 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package client
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNearestVisibility(t *testing.T) {
+	tests := []struct {
+		name string
+		v    Visibility
+		list []Visibility
+		want Visibility
+	}{
+		{"same code", GitlabIsPublic, []Visibility{GistIsSecret, GistIsPublic}, GistIsPublic},
+		{"same num", GitlabIsPrivate, []Visibility{GistIsSecret, GistIsPublic}, GistIsSecret},
+		{"internal to gist", GitlabIsInternal, []Visibility{GistIsSecret, GistIsPublic}, GistIsSecret},
+		{"secret to gitlab", GistIsSecret, []Visibility{GitlabIsPrivate, GitlabIsPublic, GitlabIsInternal}, GitlabIsPrivate},
+		{"most restricted", Visibility{code: "unknown", num: 9}, []Visibility{LocalIsPublic, LocalIsPrivate}, LocalIsPrivate},
+		{"empty list", GistIsPublic, nil, Visibility{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NearestVisibility(tt.v, tt.list); got != tt.want {
+				t.Errorf("NearestVisibility() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisibilityJSON(t *testing.T) {
+	for _, v := range []Visibility{GistIsSecret, GitlabIsInternal, LocalIsPublic, {}} {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got Visibility
+		if err = json.Unmarshal(data, &got); err != nil {
+			t.Fatal(err)
+		}
+
+		if got != v {
+			t.Errorf("json round trip of %s = %v, want %v", data, got, v)
+		}
+	}
+}
@@ -4,7 +4,11 @@
 
 package client
 
-import "strings"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
 
 // replaceNewline
 func replaceNewline(str, nlcode string) string {
@@ -20,3 +24,28 @@ func boolAddr(b bool) *bool {
 	boolVar := b
 	return &boolVar
 }
+
+// expandHome expands `~` at the beginning of path to the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, path[1:])
+}
+
+// splitScopes splits the comma separated scopes. ex) "gist, repo"
+func splitScopes(header string) (scopes []string) {
+	for _, s := range strings.Split(header, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+
+	return
+}
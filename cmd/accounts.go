@@ -0,0 +1,78 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/blacknon/snipt/client"
+	"github.com/urfave/cli/v2"
+)
+
+// CmdAccounts
+var CmdAccounts = cli.Command{
+	Name:    "accounts",
+	Aliases: []string{"doctor"},
+	Usage:   "validate the access token of every account in config, and show its scopes.",
+	Action:  cmdActionAccounts,
+}
+
+func cmdActionAccounts(c *cli.Context) (err error) {
+	// Get **config data**
+	conf, err := loadConfig(getFullPath(c.String("config")))
+	if err != nil {
+		return
+	}
+
+	// Create client without the cache, to initialize every account.
+	cl := client.Client{Timeout: c.Duration("timeout"), Verbose: getVerboseWriter(c)}
+	initErr := cl.Init(c.Context, conf)
+
+	failed := 0
+	accounts := cl.Accounts()
+	for _, a := range accounts {
+		if a.Err != nil {
+			failed++
+			fmt.Printf("NG  %s\n", a.Name)
+			fmt.Printf("    error:    %s\n", errors.Unwrap(a.Err))
+			continue
+		}
+
+		fmt.Printf("OK  %s\n", a.Name)
+		fmt.Printf("    platform: %s\n", a.Client.GetPlatformName())
+
+		// show scopes
+		scopeLister, ok := a.Client.(client.TokenScopeLister)
+		if !ok {
+			continue
+		}
+
+		scopes, serr := scopeLister.ListTokenScopes(c.Context)
+		switch {
+		case serr != nil:
+			fmt.Printf("    scopes:   (cannot get: %s)\n", serr)
+		case len(scopes) == 0:
+			fmt.Printf("    scopes:   (not reported)\n")
+		default:
+			fmt.Printf("    scopes:   %s\n", strings.Join(scopes, ", "))
+		}
+	}
+
+	if failed > 0 {
+		err = fmt.Errorf("%d of %d accounts failed to initialize", failed, len(accounts))
+
+		// all accounts failed: exit with the code of the errors. ex) ExitAuth
+		code := ExitPartial
+		if failed == len(accounts) {
+			code = getExitCode(c.Context, initErr)
+		}
+
+		return withExitCode(code, err)
+	}
+
+	return
+}
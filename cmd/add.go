@@ -4,16 +4,100 @@
 
 package cmd
 
-import "github.com/urfave/cli/v2"
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
 
 // CmdAdd
 var CmdAdd = cli.Command{
-	Name:   "add",
-	Usage:  "add snippet file to remote snippet.",
-	Action: cmdActionAdd,
+	Name:      "add",
+	Usage:     "add snippet file to remote snippet.",
+	Action:    cmdActionAdd,
+	ArgsUsage: "FILE...",
+	Flags: append([]cli.Flag{
+		// -s
+		CommonFlagViewSecret,
+	}, CommonFlagsSelect...),
 }
 
 func cmdActionAdd(c *cli.Context) (err error) {
+	// check args count
+	if c.NArg() == 0 {
+		err = newUsageError("no arguments")
+		c.App.OnUsageError(c, err, true)
+		return
+	}
+
+	// get args
+	args := c.Args().Slice()
+	pathList, err := getPathList(args)
+	if err != nil {
+		return
+	}
+
+	// generate SnippetData from pathList
+	snippetFileDataList, err := createSnippetData(pathList)
+	if err != nil {
+		return
+	}
+
+	// Get **config data** and **client.Client**
+	conf, cl, err := clinetInit(c)
+	if err != nil {
+		return
+	}
+
+	// Get List
+	list, err := getSnippetList(c.Context, &cl, false, c.Bool("secret"))
+	if err != nil {
+		return
+	}
+
+	// Select target snippet
+	urlList, err := selectSnippetURL(c, conf.General.SelectCmd, &cl, list, nil)
+	if err != nil {
+		return
+	}
+
+	updatedList := []string{}
+	for _, url := range urlList {
+		// Get SnippetData
+		snippetData, err := cl.Get(c.Context, url)
+		if err != nil {
+			return err
+		}
+
+		if snippetData.URL == "" {
+			return newNotFoundError("snippet not found: %s", url)
+		}
+
+		// append files
+		for _, f := range snippetFileDataList {
+			for _, sf := range snippetData.Files {
+				if f.Path == sf.Path {
+					return fmt.Errorf("file %s already exists in %s. use `update` subcommand", f.Path, url)
+				}
+			}
+
+			snippetData.Files = append(snippetData.Files, f)
+		}
+
+		// update
+		snippets, err := cl.Update(c.Context, url, snippetData)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range snippets {
+			updatedList = append(updatedList, s.URL)
+		}
+	}
+
+	for _, u := range updatedList {
+		fmt.Printf("Snippet Update: %s\n", u)
+	}
 
 	return
 }
@@ -3,3 +3,223 @@
 // that can be found in the LICENSE file.
 
 package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/blacknon/snipt/client"
+	"github.com/blacknon/snipt/config"
+	"github.com/urfave/cli/v2"
+)
+
+// commentFlags
+var commentFlags = append([]cli.Flag{
+	// -s
+	CommonFlagViewSecret,
+}, CommonFlagsSelect...)
+
+// commentFlagMessage ... -m, --message
+var commentFlagMessage = &cli.StringFlag{
+	Name:    "message",
+	Aliases: []string{"m"},
+	Usage:   "specify comment `TEXT`. if not specified, open the editor.",
+}
+
+// commentFlagId ... --comment-id
+var commentFlagId = &cli.StringFlag{
+	Name:  "comment-id",
+	Usage: "specify comment `ID`, instead of selecting it.",
+}
+
+// CmdComment
+var CmdComment = cli.Command{
+	Name:  "comment",
+	Usage: "list and post comments of remote snippet. gitlab can only comment on project snippets.",
+	Subcommands: []*cli.Command{
+		{
+			Name:   "list",
+			Usage:  "list comments of remote snippet.",
+			Action: cmdActionCommentList,
+			Flags:  commentFlags,
+		},
+		{
+			Name:   "add",
+			Usage:  "add comment to remote snippet.",
+			Action: cmdActionCommentAdd,
+			Flags:  append([]cli.Flag{commentFlagMessage}, commentFlags...),
+		},
+		{
+			Name:   "edit",
+			Usage:  "edit comment of remote snippet.",
+			Action: cmdActionCommentEdit,
+			Flags:  append([]cli.Flag{commentFlagId, commentFlagMessage}, commentFlags...),
+		},
+		{
+			Name:   "delete",
+			Usage:  "delete comment of remote snippet.",
+			Action: cmdActionCommentDelete,
+			Flags:  append([]cli.Flag{commentFlagId}, commentFlags...),
+		},
+	},
+}
+
+func cmdActionCommentList(c *cli.Context) (err error) {
+	_, cl, url, err := getSnippetTarget(c, nil)
+	if err != nil {
+		return
+	}
+
+	comments, err := cl.ListComments(c.Context, url)
+	if err != nil {
+		return
+	}
+
+	for _, cm := range comments {
+		fmt.Printf("#%s %s (%s)\n", cm.Id, cm.Author, cm.CreatedAt.Format("2006/01/02 15:04:05"))
+		fmt.Println(cm.Body)
+		fmt.Println()
+	}
+
+	return
+}
+
+func cmdActionCommentAdd(c *cli.Context) (err error) {
+	conf, cl, url, err := getSnippetTarget(c, nil)
+	if err != nil {
+		return
+	}
+
+	body, err := getCommentBody(c, conf, "")
+	if err != nil {
+		return
+	}
+
+	comment, err := cl.AddComment(c.Context, url, body)
+	if err != nil {
+		return
+	}
+
+	fmt.Printf("Comment added: %s #%s\n", url, comment.Id)
+
+	return
+}
+
+func cmdActionCommentEdit(c *cli.Context) (err error) {
+	conf, cl, url, err := getSnippetTarget(c, nil)
+	if err != nil {
+		return
+	}
+
+	comment, err := selectComment(c, conf, cl, url)
+	if err != nil {
+		return
+	}
+
+	body, err := getCommentBody(c, conf, comment.Body)
+	if err != nil {
+		return
+	}
+
+	comment, err = cl.EditComment(c.Context, url, comment.Id, body)
+	if err != nil {
+		return
+	}
+
+	fmt.Printf("Comment updated: %s #%s\n", url, comment.Id)
+
+	return
+}
+
+func cmdActionCommentDelete(c *cli.Context) (err error) {
+	conf, cl, url, err := getSnippetTarget(c, nil)
+	if err != nil {
+		return
+	}
+
+	comment, err := selectComment(c, conf, cl, url)
+	if err != nil {
+		return
+	}
+
+	err = cl.DeleteComment(c.Context, url, comment.Id)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Comment deleted: %s #%s\n", url, comment.Id)
+
+	return
+}
+
+// selectComment
+func selectComment(c *cli.Context, conf config.Config, cl client.Client, url string) (comment client.SnippetComment, err error) {
+	comments, err := cl.ListComments(c.Context, url)
+	if err != nil {
+		return
+	}
+
+	id := c.String("comment-id")
+	if id == "" {
+		// Create list
+		var filterText string
+		for _, cm := range comments {
+			line := strings.SplitN(cm.Body, "\n", 2)[0]
+			t := fmt.Sprintln(cm.Id, cm.Author+":", line)
+			filterText += t
+		}
+
+		// Run filter command
+		text, ferr := filter(conf.General.SelectCmd, []string{}, filterText)
+		if ferr != nil {
+			return comment, ferr
+		}
+
+		if len(text) != 1 || text[0] == "" {
+			err = newUsageError("select one comment")
+			return
+		}
+
+		id = strings.Split(text[0], " ")[0]
+	}
+
+	for _, cm := range comments {
+		if cm.Id == id {
+			return cm, nil
+		}
+	}
+
+	err = newNotFoundError("comment not found: #%s", id)
+
+	return
+}
+
+// getCommentBody
+func getCommentBody(c *cli.Context, conf config.Config, body string) (result string, err error) {
+	if c.IsSet("message") {
+		result = c.String("message")
+	} else {
+		tmpfile, eerr := edit(conf.General.Editor, []string{}, "comment.md", []byte(body))
+		if tmpfile != "" {
+			defer os.Remove(tmpfile)
+		}
+		if eerr != nil {
+			return result, eerr
+		}
+
+		data, rerr := read(tmpfile)
+		if rerr != nil {
+			return result, rerr
+		}
+
+		result = string(data)
+	}
+
+	result = strings.TrimRight(result, "\n")
+	if result == "" {
+		err = fmt.Errorf("empty comment")
+	}
+
+	return
+}
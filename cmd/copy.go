@@ -3,3 +3,110 @@
 // that can be found in the LICENSE file.
 
 package cmd
+
+import (
+	"fmt"
+
+	"github.com/blacknon/snipt/client"
+	"github.com/urfave/cli/v2"
+)
+
+// CmdCopy
+var CmdCopy = cli.Command{
+	Name:   "copy",
+	Usage:  "copy remote snippet to other platforms. visibility is mapped to the nearest value the destination supports.",
+	Action: cmdActionCopy,
+	Flags: append([]cli.Flag{
+		// -s
+		CommonFlagViewSecret,
+
+		// -p
+		&cli.BoolFlag{
+			Name:    "project_snippet",
+			Aliases: []string{"p"},
+			Usage:   "output to a list so that it can also support the creation of Gitlab's Project Snippet and Bitbucket's Workspace Snippet.",
+		},
+
+		// --to PLATFORM
+		CommonFlagSelectPlatform,
+	}, CommonFlagsSelect...),
+}
+
+func cmdActionCopy(c *cli.Context) (err error) {
+	// Get **config data** and **client.Client**
+	conf, cl, err := clinetInit(c)
+	if err != nil {
+		return
+	}
+
+	// Get List
+	list, err := getSnippetList(c.Context, &cl, false, c.Bool("secret"))
+	if err != nil {
+		return
+	}
+
+	// Select source snippet
+	urlList, err := selectSnippetURL(c, conf.General.SelectCmd, &cl, list, nil)
+	if err != nil {
+		return
+	}
+
+	// Get source SnippetData
+	sourceList := []client.SnippetData{}
+	for _, url := range urlList {
+		snippetData, err := cl.Get(c.Context, url)
+		if err != nil {
+			return err
+		}
+
+		if snippetData.URL == "" {
+			return newNotFoundError("snippet not found: %s", url)
+		}
+
+		sourceList = append(sourceList, snippetData)
+	}
+
+	// Select platform to copy snippet
+	platformList, err := cl.PlatformList(c.Context, c.Bool("project_snippet"))
+	if err != nil {
+		return err
+	}
+
+	text, err := selectPlatform(c, conf.General.SelectCmd, platformList)
+	if err != nil {
+		return
+	}
+
+	for _, source := range sourceList {
+		for _, t := range text {
+			// Create Snippet
+			snippetData := client.SnippetData{
+				Title:       source.Title,
+				Description: source.Description,
+				Files:       []client.SnippetFileData{},
+			}
+
+			for _, f := range source.Files {
+				snippetData.Files = append(snippetData.Files, client.SnippetFileData{
+					Path:     f.Path,
+					Contents: f.Contents,
+				})
+			}
+
+			// set visibility
+			vl := cl.VisibilityListFromPlatform(t)
+			snippetData.Visibility = client.NearestVisibility(source.Visibility, vl)
+
+			snippets, eErr := cl.Create(c.Context, t, snippetData)
+			if eErr != nil {
+				return eErr
+			}
+
+			for _, s := range snippets {
+				fmt.Printf("%s -> %s\n", source.URL, s.URL)
+			}
+		}
+	}
+
+	return
+}
@@ -29,9 +29,12 @@ var CmdCreate = cli.Command{
 		&cli.BoolFlag{
 			Name:    "project_snippet",
 			Aliases: []string{"p"},
-			Usage:   "output to a list so that it can also support the creation of Gitlab's Project Snippet.",
+			Usage:   "output to a list so that it can also support the creation of Gitlab's Project Snippet and Bitbucket's Workspace Snippet.",
 		},
 
+		// --to PLATFORM
+		CommonFlagSelectPlatform,
+
 		// -A
 		// &cli.BoolFlag{
 		// 	Name:    "ask",
@@ -44,7 +47,7 @@ var CmdCreate = cli.Command{
 func cmdActionCreate(c *cli.Context) (err error) {
 	// check args count
 	if c.NArg() == 0 {
-		err = fmt.Errorf("no arguments")
+		err = newUsageError("no arguments")
 		c.App.OnUsageError(c, err, true)
 		return
 	}
@@ -62,26 +65,18 @@ func cmdActionCreate(c *cli.Context) (err error) {
 	}
 
 	// Get **config data** and **client.Client**
-	cf := c.String("config")
-	conf, cl, err := clinetInit(cf)
+	conf, cl, err := clinetInit(c)
 	if err != nil {
 		return
 	}
 
 	// Select platform to create snippet
-	platformList, err := cl.PlatformList(c.Bool("project_snippet"))
+	platformList, err := cl.PlatformList(c.Context, c.Bool("project_snippet"))
 	if err != nil {
 		return err
 	}
 
-	var filterText string
-	for _, p := range platformList {
-		t := fmt.Sprintln(p)
-		filterText += t
-	}
-
-	// Run filter command
-	text, err := filter(conf.General.SelectCmd, []string{}, filterText)
+	text, err := selectPlatform(c, conf.General.SelectCmd, platformList)
 	if err != nil {
 		return
 	}
@@ -111,18 +106,20 @@ func cmdActionCreate(c *cli.Context) (err error) {
 			visibility, eErr := getSelectVisibility(vl)
 
 			if eErr != nil {
-				return err
+				return eErr
 			}
 
 			snippetData.Visibility = visibility
 		}
 
-		rawURL, eErr := cl.Create(t, snippetData)
+		snippets, eErr := cl.Create(c.Context, t, snippetData)
 		if eErr != nil {
 			return eErr
 		}
 
-		rawURLs = append(rawURLs, rawURL...)
+		for _, s := range snippets {
+			rawURLs = append(rawURLs, s.URL)
+		}
 
 	}
 
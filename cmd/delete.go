@@ -7,50 +7,44 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/urfave/cli/v2"
 )
 
 // CmdDelete
 var CmdDelete = cli.Command{
-	Name:   "delete",
-	Usage:  "delete remote snippet data.",
-	Action: cmdActionDelete,
-	Flags: []cli.Flag{
+	Name:      "delete",
+	Usage:     "delete remote snippet data.",
+	Action:    cmdActionDelete,
+	ArgsUsage: "[URL...]",
+	Flags: append([]cli.Flag{
 		// -s
 		CommonFlagViewSecret,
-	},
+	}, CommonFlagsSelect...),
 }
 
 func cmdActionDelete(c *cli.Context) (err error) {
 	// Get **config data** and **client.Client**
-	cf := c.String("config")
-	conf, cl, err := clinetInit(cf)
+	conf, cl, err := clinetInit(c)
 	if err != nil {
 		return
 	}
 
 	// Get List
-	list := cl.List(false, c.Bool("secret"))
-
-	// Create list
-	var filterText string
-	for _, l := range list {
-		t := fmt.Sprintln(l.URL, l.Platform, l.Title)
-		filterText += t
+	list, err := getSnippetList(c.Context, &cl, false, c.Bool("secret"))
+	if err != nil {
+		return
 	}
 
-	// Run filter command
-	text, err := filter(conf.General.SelectCmd, []string{}, filterText)
-
-	for _, t := range text {
-		splitText := strings.Split(t, " ")
-		url := splitText[0]
+	// Select snippet
+	urlList, err := selectSnippetURL(c, conf.General.SelectCmd, &cl, list, c.Args().Slice())
+	if err != nil {
+		return
+	}
 
-		err := cl.Delete(url)
+	for _, url := range urlList {
+		err := cl.Delete(c.Context, url)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
 			return err
 		}
 
@@ -0,0 +1,448 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/blacknon/snipt/client"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+// diffContextLines is the number of context lines of the unified diff.
+const diffContextLines = 3
+
+// errDifferent is returned by diff when the local files and the snippet are different.
+// It is not printed, as diff(1).
+var errDifferent = withExitCode(ExitDifferent, errors.New("local files and snippet are different"))
+
+// escape sequences of the diff colors
+const (
+	diffColorHeader = "\x1b[1m"
+	diffColorHunk   = "\x1b[36m"
+	diffColorDelete = "\x1b[31m"
+	diffColorInsert = "\x1b[32m"
+	diffColorReset  = "\x1b[0m"
+)
+
+// diffFlagColor ... --color WHEN
+var diffFlagColor = &cli.StringFlag{
+	Name:  "color",
+	Usage: "colorize the diff. `WHEN` is auto, always or never. auto colorizes only when stdout is terminal.",
+	Value: "auto",
+}
+
+// diffFlagAsUpdate ... --as-update
+var diffFlagAsUpdate = &cli.BoolFlag{
+	Name:  "as-update",
+	Usage: "show only the changes that update makes with the same files. the files only in one side are not shown.",
+}
+
+// diffFlagConfirm ... --confirm
+var diffFlagConfirm = &cli.BoolFlag{
+	Name:  "confirm",
+	Usage: "show the diff of the files, and confirm before updating remote snippet.",
+}
+
+// CmdDiff
+var CmdDiff = cli.Command{
+	Name:      "diff",
+	Usage:     "show the differences between local files and remote snippet. exit status is 1 if there are differences.",
+	Action:    cmdActionDiff,
+	ArgsUsage: "FILE...",
+	Flags: append([]cli.Flag{
+		// -f
+		CommonFlagSnippetFile,
+
+		// -s
+		CommonFlagViewSecret,
+
+		// --color WHEN
+		diffFlagColor,
+
+		// --as-update
+		diffFlagAsUpdate,
+	}, CommonFlagsSelect...),
+}
+
+func cmdActionDiff(c *cli.Context) (err error) {
+	// the errors are returned as trouble, not to be taken as differences
+	defer func() {
+		if err != nil && err != errDifferent && getExitCode(c.Context, err) == ExitError {
+			err = withExitCode(ExitTrouble, err)
+		}
+	}()
+
+	// check args count
+	if c.NArg() == 0 {
+		err = newUsageError("no arguments")
+		c.App.OnUsageError(c, err, true)
+		return
+	}
+
+	color, err := isDiffColor(c)
+	if err != nil {
+		return
+	}
+
+	// generate SnippetData from pathList
+	pathList, err := getPathList(c.Args().Slice())
+	if err != nil {
+		return
+	}
+
+	localFiles, err := createSnippetData(pathList)
+	if err != nil {
+		return
+	}
+
+	// Get **config data** and **client.Client**
+	conf, cl, err := clinetInit(c)
+	if err != nil {
+		return
+	}
+
+	// Get List
+	list, err := getSnippetList(c.Context, &cl, c.Bool("file"), c.Bool("secret"))
+	if err != nil {
+		return
+	}
+
+	// Select snippet
+	selectedList, err := selectSnippetURL(c, conf.General.SelectCmd, &cl, list, nil)
+	if err != nil {
+		return
+	}
+
+	isChanged := false
+	for _, url := range selectedList {
+		// Get SnippetData
+		snippetData, err := cl.Get(c.Context, url)
+		if err != nil {
+			return err
+		}
+
+		// compare with the selected file only
+		remoteFiles := snippetData.Files
+		if c.Bool("file") {
+			remoteFiles = []client.SnippetFileData{}
+			for _, f := range snippetData.Files {
+				if url == f.Filter {
+					remoteFiles = append(remoteFiles, f)
+				}
+			}
+		}
+
+		// the files only in one side are shown as added or removed.
+		// with --as-update, compare as update does. update only replaces the files in remote snippet.
+		newFiles := localFiles
+		if c.Bool("as-update") {
+			newFiles, _ = replaceSnippetFiles(remoteFiles, localFiles)
+		}
+
+		if writeSnippetDiff(os.Stdout, url, remoteFiles, newFiles, color) {
+			isChanged = true
+		}
+	}
+
+	if isChanged {
+		return errDifferent
+	}
+
+	return
+}
+
+// isDiffColor returns true if the diff is colorized, according to --color.
+func isDiffColor(c *cli.Context) (bool, error) {
+	switch c.String("color") {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto", "":
+		return term.IsTerminal(int(os.Stdout.Fd())), nil
+	}
+
+	return false, newUsageError("invalid --color: %s", c.String("color"))
+}
+
+// confirmSnippetDiff shows the diff of the snippet files, and asks whether to update the snippet.
+func confirmSnippetDiff(url string, oldFiles, newFiles []client.SnippetFileData) bool {
+	color := term.IsTerminal(int(os.Stdout.Fd()))
+	if !writeSnippetDiff(os.Stdout, url, oldFiles, newFiles, color) {
+		fmt.Fprintf(os.Stderr, "No changes in files: %s\n", url)
+	}
+
+	return askYesNo(fmt.Sprintf("Update %s ?", url))
+}
+
+// writeSnippetDiff writes the unified diff from oldFiles to newFiles of the snippet of url.
+// Files are compared by path, and files only in one side are shown as added or removed.
+// It returns true if there are differences.
+func writeSnippetDiff(w io.Writer, url string, oldFiles, newFiles []client.SnippetFileData, color bool) (isChanged bool) {
+	oldContents := map[string][]byte{}
+	paths := []string{}
+	for _, f := range oldFiles {
+		oldContents[f.Path] = f.Contents
+		paths = append(paths, f.Path)
+	}
+
+	newContents := map[string][]byte{}
+	for _, f := range newFiles {
+		if _, ok := oldContents[f.Path]; !ok {
+			paths = append(paths, f.Path)
+		}
+		newContents[f.Path] = f.Contents
+	}
+
+	var buf bytes.Buffer
+	for _, p := range paths {
+		oldData, isOld := oldContents[p]
+		newData, isNew := newContents[p]
+		if isOld && isNew && bytes.Equal(oldData, newData) {
+			continue
+		}
+
+		oldName, newName := "a/"+p, "b/"+p
+		if !isOld {
+			oldName = "/dev/null"
+		}
+		if !isNew {
+			newName = "/dev/null"
+		}
+
+		writeFileDiff(&buf, oldName, newName, oldData, newData, color)
+	}
+
+	if buf.Len() == 0 {
+		return false
+	}
+
+	fmt.Fprintln(w, colorize(color, diffColorHeader, "diff "+url))
+	w.Write(buf.Bytes())
+
+	return true
+}
+
+// writeFileDiff writes the unified diff of a file.
+func writeFileDiff(w io.Writer, oldName, newName string, oldData, newData []byte, color bool) {
+	fmt.Fprintln(w, colorize(color, diffColorHeader, "--- "+oldName))
+	fmt.Fprintln(w, colorize(color, diffColorHeader, "+++ "+newName))
+
+	if bytes.IndexByte(oldData, 0) >= 0 || bytes.IndexByte(newData, 0) >= 0 {
+		fmt.Fprintln(w, "Binary files differ")
+		return
+	}
+
+	ops := diffLines(splitLines(oldData), splitLines(newData))
+
+	// line numbers before each op
+	oldLine := make([]int, len(ops)+1)
+	newLine := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if op.kind != '+' {
+			oldLine[i+1]++
+		}
+		if op.kind != '-' {
+			newLine[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == ' ' {
+			continue
+		}
+
+		// extend the hunk while the next change is within the context
+		start := max(0, i-diffContextLines)
+		end := i
+		for j := i; j < len(ops) && j <= end+2*diffContextLines+1; j++ {
+			if ops[j].kind != ' ' {
+				end = j
+			}
+		}
+		end = min(len(ops), end+diffContextLines+1)
+
+		fmt.Fprintln(w, colorize(color, diffColorHunk, fmt.Sprintf("@@ -%s +%s @@",
+			formatHunkRange(oldLine[start], oldLine[end]-oldLine[start]),
+			formatHunkRange(newLine[start], newLine[end]-newLine[start]),
+		)))
+
+		for _, op := range ops[start:end] {
+			line := string(op.kind) + strings.TrimSuffix(op.line, "\n")
+			switch op.kind {
+			case '-':
+				line = colorize(color, diffColorDelete, line)
+			case '+':
+				line = colorize(color, diffColorInsert, line)
+			}
+			fmt.Fprintln(w, line)
+
+			if !strings.HasSuffix(op.line, "\n") {
+				fmt.Fprintln(w, "\\ No newline at end of file")
+			}
+		}
+
+		i = end - 1
+	}
+}
+
+// formatHunkRange formats the range of the hunk header. before is the number of lines before the hunk.
+func formatHunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	}
+
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// colorize
+func colorize(color bool, code, s string) string {
+	if !color {
+		return s
+	}
+
+	return code + s + diffColorReset
+}
+
+// splitLines splits data into lines. Each line keeps its newline.
+func splitLines(data []byte) (lines []string) {
+	lines = strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return
+}
+
+// diffOp is a line of the edit script. kind is ' ' (equal), '-' (delete) or '+' (insert).
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// diffLines returns the shortest edit script from a to b, by the linear space variant of the Myers algorithm.
+func diffLines(a, b []string) (ops []diffOp) {
+	return appendDiffOps(make([]diffOp, 0, len(a)+len(b)), a, b)
+}
+
+// appendDiffOps appends the edit script from a to b to ops.
+// a and b are divided at the middle snake, so that only O(len(a)+len(b)) space is used.
+func appendDiffOps(ops []diffOp, a, b []string) []diffOp {
+	// common prefix
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		ops = append(ops, diffOp{kind: ' ', line: a[0]})
+		a, b = a[1:], b[1:]
+	}
+
+	// common suffix
+	s := 0
+	for s < len(a) && s < len(b) && a[len(a)-1-s] == b[len(b)-1-s] {
+		s++
+	}
+	suffix := a[len(a)-s:]
+	a, b = a[:len(a)-s], b[:len(b)-s]
+
+	switch {
+	case len(a) == 0:
+		for _, line := range b {
+			ops = append(ops, diffOp{kind: '+', line: line})
+		}
+	case len(b) == 0:
+		for _, line := range a {
+			ops = append(ops, diffOp{kind: '-', line: line})
+		}
+	default:
+		x, y, u, v := findMiddleSnake(a, b)
+
+		ops = appendDiffOps(ops, a[:x], b[:y])
+		for _, line := range a[x:u] {
+			ops = append(ops, diffOp{kind: ' ', line: line})
+		}
+		ops = appendDiffOps(ops, a[u:], b[v:])
+	}
+
+	for _, line := range suffix {
+		ops = append(ops, diffOp{kind: ' ', line: line})
+	}
+
+	return ops
+}
+
+// findMiddleSnake returns the middle snake (x, y) -> (u, v) of the shortest edit script from a to b.
+// The paths are searched from the start and from the end at the same time, until they overlap.
+func findMiddleSnake(a, b []string) (x, y, u, v int) {
+	n, m := len(a), len(b)
+	delta := n - m
+	isOdd := delta%2 != 0
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+
+	// vf[offset+k] is the furthest x on the diagonal k from the start.
+	// vb[offset+k] is the furthest x on the diagonal k from the end, in the reversed a and b.
+	vf := make([]int, 2*offset+1)
+	vb := make([]int, 2*offset+1)
+
+	for d := 0; d <= maxD; d++ {
+		// forward
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[offset+k-1] < vf[offset+k+1]) {
+				x = vf[offset+k+1]
+			} else {
+				x = vf[offset+k-1] + 1
+			}
+
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			vf[offset+k] = x
+
+			// overlap with the backward path of d-1
+			if rk := delta - k; isOdd && -(d-1) <= rk && rk <= d-1 && x+vb[offset+rk] >= n {
+				return x0, y0, x, y
+			}
+		}
+
+		// backward
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vb[offset+k-1] < vb[offset+k+1]) {
+				x = vb[offset+k+1]
+			} else {
+				x = vb[offset+k-1] + 1
+			}
+
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < m && a[n-1-x] == b[m-1-y] {
+				x++
+				y++
+			}
+			vb[offset+k] = x
+
+			// overlap with the forward path of d
+			if fk := delta - k; !isOdd && -d <= fk && fk <= d && x+vf[offset+fk] >= n {
+				return n - x, m - y, n - x0, m - y0
+			}
+		}
+	}
+
+	// not reached
+	return 0, 0, 0, 0
+}
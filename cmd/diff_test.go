@@ -0,0 +1,237 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blacknon/snipt/client"
+)
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
+
+// checkDiffOps checks that ops converts a to b with the fewest edits.
+func checkDiffOps(t *testing.T, a, b []string, ops []diffOp) {
+	t.Helper()
+
+	var gotA, gotB []string
+	edits := 0
+	for _, op := range ops {
+		if op.kind != '+' {
+			gotA = append(gotA, op.line)
+		}
+		if op.kind != '-' {
+			gotB = append(gotB, op.line)
+		}
+		if op.kind != ' ' {
+			edits++
+		}
+	}
+
+	if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+		t.Fatalf("diffLines(%q, %q) = %q, does not reconstruct the input", a, b, ops)
+	}
+
+	if want := len(a) + len(b) - 2*lcsLength(a, b); edits != want {
+		t.Errorf("diffLines(%q, %q) has %d edits, want %d", a, b, edits, want)
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := [][2]string{
+		{"", ""},
+		{"", "a\n"},
+		{"a\n", ""},
+		{"a\n", "a\n"},
+		{"a\n", "b\n"},
+		{"a\nb\nc\n", "a\nc\n"},
+		{"a\nb\nc\na\nb\nb\na\n", "c\nb\na\nb\na\nc\n"},
+		{"a\nb\n", "b\na\n"},
+		{"a\nb", "a\nb\n"},
+	}
+
+	for _, tt := range tests {
+		a, b := []string{}, []string{}
+		if tt[0] != "" {
+			a = splitLines([]byte(tt[0]))
+		}
+		if tt[1] != "" {
+			b = splitLines([]byte(tt[1]))
+		}
+
+		checkDiffOps(t, a, b, diffLines(a, b))
+	}
+
+	// random inputs, with few kinds of lines so that there are many common lines
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a := make([]string, r.Intn(30))
+		for j := range a {
+			a[j] = string(rune('a' + r.Intn(4)))
+		}
+		b := make([]string, r.Intn(30))
+		for j := range b {
+			b[j] = string(rune('a' + r.Intn(4)))
+		}
+
+		checkDiffOps(t, a, b, diffLines(a, b))
+	}
+}
+
+func TestWriteSnippetDiff(t *testing.T) {
+	oldFiles := []client.SnippetFileData{
+		{Path: "a.sh", Contents: []byte("1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n")},
+		{Path: "b.sh", Contents: []byte("same\n")},
+		{Path: "c.sh", Contents: []byte("removed\n")},
+	}
+	newFiles := []client.SnippetFileData{
+		{Path: "a.sh", Contents: []byte("1\n2\n3\n4\nfive\n6\n7\n8\n9\n10")},
+		{Path: "b.sh", Contents: []byte("same\n")},
+		{Path: "d.sh", Contents: []byte("added\n")},
+	}
+
+	want := `diff url
+--- a/a.sh
++++ b/a.sh
+@@ -2,9 +2,9 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+ 9
+-10
++10
+\ No newline at end of file
+--- a/c.sh
++++ /dev/null
+@@ -1 +0,0 @@
+-removed
+--- /dev/null
++++ b/d.sh
+@@ -0,0 +1 @@
++added
+`
+
+	var buf bytes.Buffer
+	if !writeSnippetDiff(&buf, "url", oldFiles, newFiles, false) {
+		t.Fatal("writeSnippetDiff() = false, want true")
+	}
+	if buf.String() != want {
+		t.Errorf("writeSnippetDiff() =\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	// no differences
+	buf.Reset()
+	if writeSnippetDiff(&buf, "url", oldFiles, oldFiles, false) || buf.Len() != 0 {
+		t.Errorf("writeSnippetDiff() of same files = %q", buf.String())
+	}
+}
+
+func TestReplaceSnippetFiles(t *testing.T) {
+	remote := []client.SnippetFileData{
+		{Path: "a.sh", Contents: []byte("a")},
+		{Path: "b.sh", Contents: []byte("b")},
+		{Path: "c.sh", Contents: []byte("c")},
+	}
+	local := []client.SnippetFileData{
+		{Path: "c.sh", Contents: []byte("new c")},
+		{Path: "a.sh", Contents: []byte("new a")},
+		{Path: "d.sh", Contents: []byte("d")},
+	}
+
+	files, missingPaths := replaceSnippetFiles(remote, local)
+
+	var got []string
+	for _, f := range files {
+		got = append(got, f.Path+"="+string(f.Contents))
+	}
+
+	if want := "a.sh=new a,b.sh=b,c.sh=new c"; strings.Join(got, ",") != want {
+		t.Errorf("replaceSnippetFiles() = %s, want %s", strings.Join(got, ","), want)
+	}
+	if strings.Join(missingPaths, ",") != "d.sh" {
+		t.Errorf("replaceSnippetFiles() missingPaths = %v, want [d.sh]", missingPaths)
+	}
+}
+
+func TestCmdDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	conf := newTestLocalConfig(t, dir, true)
+
+	l := &client.LocalClient{}
+	if err := l.Init(ctx, dir, true); err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := l.Create(ctx, client.SnippetData{Title: "title", Files: []client.SnippetFileData{
+		{Path: "a.sh", Contents: []byte("a\n")},
+		{Path: "b.sh", Contents: []byte("b\n")},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	work := t.TempDir()
+	local := filepath.Join(work, "a.sh")
+	if err = os.WriteFile(local, []byte("a\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// b.sh is only in remote snippet, and shown as removed
+	err = App.RunContext(ctx, []string{"snipt", "--config", conf, "diff", "-s", "--color", "never", "-u", created.URL, local})
+	if err != errDifferent || getExitCode(ctx, err) != ExitDifferent {
+		t.Errorf("diff error = %v, want errDifferent", err)
+	}
+
+	// update does not change b.sh
+	err = App.RunContext(ctx, []string{"snipt", "--config", conf, "diff", "-s", "--as-update", "-u", created.URL, local})
+	if err != nil {
+		t.Errorf("diff --as-update error = %v, want nil", err)
+	}
+
+	// errors are not taken as differences
+	broken := filepath.Join(work, "broken.toml")
+	if err = os.WriteFile(broken, []byte("[[Local]\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err = App.RunContext(ctx, []string{"snipt", "--config", broken, "diff", "-s", "-u", created.URL, local})
+	if got := getExitCode(ctx, err); err == nil || got != ExitTrouble {
+		t.Errorf("diff with broken config error = %v, exit code = %d, want %d", err, got, ExitTrouble)
+	}
+}
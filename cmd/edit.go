@@ -7,7 +7,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/blacknon/snipt/client"
 	"github.com/urfave/cli/v2"
@@ -15,10 +14,11 @@ import (
 
 // CmdEdit
 var CmdEdit = cli.Command{
-	Name:   "edit",
-	Usage:  "edit remote snippet file. use the command specified in `editor` in config.toml for editing.",
-	Action: cmdActionEdit,
-	Flags: []cli.Flag{
+	Name:      "edit",
+	Usage:     "edit remote snippet file. use the command specified in `editor` in config.toml for editing.",
+	Action:    cmdActionEdit,
+	ArgsUsage: "[URL...]",
+	Flags: append([]cli.Flag{
 		// -v
 		CommonFlagSelecterVisibility,
 
@@ -27,50 +27,36 @@ var CmdEdit = cli.Command{
 
 		// -s
 		CommonFlagViewSecret,
-	},
-}
 
-// TODO: renameの処理を実装する(オプション？)
+		// --confirm
+		diffFlagConfirm,
+	}, CommonFlagsSelect...),
+}
 
 func cmdActionEdit(c *cli.Context) (err error) {
 	// Get **config data** and **client.Client**
-	cf := c.String("config")
-	conf, cl, err := clinetInit(cf)
+	conf, cl, err := clinetInit(c)
 	if err != nil {
 		return
 	}
 
 	// Get List
-	list := cl.List(true, c.Bool("secret"))
-
-	// Create list
-	var filterText string
-	for _, l := range list {
-		t := fmt.Sprintln(l.URL, l.Platform, l.Title)
-		filterText += t
-	}
-
-	// Run filter command
-	text, err := filter(conf.General.SelectCmd, []string{}, filterText)
+	list, err := getSnippetList(c.Context, &cl, true, c.Bool("secret"))
 	if err != nil {
 		return
 	}
 
-	// TODO: 1ライン以上選択されている場合はエラーにする？
-	if len(text) == 0 {
+	// Select snippet
+	selectedList, err := selectSnippetURL(c, conf.General.SelectCmd, &cl, list, c.Args().Slice())
+	if err != nil {
 		return
 	}
 
 	urlList := []string{}
-	for _, t := range text {
-		// create url
-		splitText := strings.Split(t, " ")
-		url := splitText[0]
-
-		snippetData, eErr := cl.Get(url)
+	for _, url := range selectedList {
+		snippetData, eErr := cl.Get(c.Context, url)
 		if eErr != nil {
-			fmt.Fprintln(os.Stderr, eErr)
-			return err
+			return eErr
 		}
 
 		// update visibility
@@ -78,13 +64,13 @@ func cmdActionEdit(c *cli.Context) (err error) {
 			// Get platorm visibility list
 			vl, eErr := cl.VisibilityListFromURL(url)
 			if eErr != nil {
-				return err
+				return eErr
 			}
 
 			// select visibility
 			visibility, eErr := getSelectVisibility(vl)
 			if eErr != nil {
-				return err
+				return eErr
 			}
 
 			snippetData.Visibility = visibility
@@ -93,18 +79,24 @@ func cmdActionEdit(c *cli.Context) (err error) {
 		// edit
 		editedFiles, eErr := editFiles(url, conf.General.Editor, []string{}, snippetData.Files)
 		if eErr != nil {
-			return
+			return eErr
+		}
+
+		// confirm the diff
+		if c.Bool("confirm") && !confirmSnippetDiff(url, snippetData.Files, editedFiles) {
+			continue
 		}
 		snippetData.Files = editedFiles
 
 		// edit data update
-		rawURLs, err := cl.Update(url, snippetData)
+		snippets, err := cl.Update(c.Context, url, snippetData)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
 			return err
 		}
 
-		urlList = append(urlList, rawURLs...)
+		for _, s := range snippets {
+			urlList = append(urlList, s.URL)
+		}
 	}
 
 	for _, u := range urlList {
@@ -0,0 +1,141 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/blacknon/snipt/client"
+	"github.com/blacknon/snipt/finder"
+	"github.com/urfave/cli/v2"
+)
+
+// Exit codes of snipt.
+const (
+	// ExitOK is returned when the command succeeded.
+	ExitOK = 0
+
+	// ExitError is returned for the errors not listed below.
+	ExitError = 1
+
+	// ExitDifferent is returned by diff when there are differences. (same as diff(1))
+	ExitDifferent = 1
+
+	// ExitTrouble is returned by diff instead of ExitError, so that the errors are not taken as differences. (same as diff(1))
+	ExitTrouble = 2
+
+	// ExitUsage is returned for the usage error. (unknown command or flag, missing arguments)
+	ExitUsage = 2
+
+	// ExitAuth is returned when the access token is invalid or has no permission.
+	ExitAuth = 3
+
+	// ExitNotFound is returned when the snippet, file, comment or platform is not found.
+	ExitNotFound = 4
+
+	// ExitPartial is returned when the command succeeded, but some accounts or platforms failed and were skipped.
+	ExitPartial = 5
+
+	// ExitNetwork is returned when the remote platform can not be reached, does not respond in time, or is rate limited.
+	ExitNetwork = 6
+
+	// ExitCancelled is returned when the selection is cancelled, or snipt is interrupted by Ctrl-C.
+	ExitCancelled = 130
+)
+
+// cliExitErrorType is the type of the error created by cli.Exit.
+// Other errors implement cli.ExitCoder too (ex: *exec.ExitError of $EDITOR), so the type is compared.
+var cliExitErrorType = reflect.TypeOf(cli.Exit("", 0))
+
+// partialFailure is set when some accounts or platforms failed and the command continued with the others.
+var partialFailure error
+
+// exitCodeError is the error with the exit code.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+// Error
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode
+func withExitCode(code int, err error) error {
+	return &exitCodeError{code: code, err: err}
+}
+
+// newUsageError
+func newUsageError(format string, a ...interface{}) error {
+	return withExitCode(ExitUsage, fmt.Errorf(format, a...))
+}
+
+// newNotFoundError
+func newNotFoundError(format string, a ...interface{}) error {
+	return withExitCode(ExitNotFound, fmt.Errorf(format, a...))
+}
+
+// getExitCode returns the exit code of err. ctx is the context of the command, canceled by signals.
+func getExitCode(ctx context.Context, err error) int {
+	if err == nil {
+		if partialFailure != nil {
+			return ExitPartial
+		}
+
+		return ExitOK
+	}
+
+	var exitCodeErr *exitCodeError
+	if errors.As(err, &exitCodeErr) {
+		return exitCodeErr.code
+	}
+
+	// canceled by user
+	if ctx.Err() != nil || errors.Is(err, finder.ErrCancelled) || errors.Is(err, terminal.InterruptErr) {
+		return ExitCancelled
+	}
+
+	// errors of urfave/cli. ex) No help topic for 'foo'
+	var exitCoder cli.ExitCoder
+	if errors.As(err, &exitCoder) && reflect.TypeOf(exitCoder) == cliExitErrorType {
+		return ExitUsage
+	}
+
+	switch {
+	case errors.Is(err, errMultipleSnippetMatched):
+		return ExitUsage
+	case errors.Is(err, errNoSnippetMatched):
+		return ExitNotFound
+	case errors.Is(err, client.ErrAuth):
+		return ExitAuth
+	case errors.Is(err, client.ErrNotFound):
+		return ExitNotFound
+	case errors.Is(err, client.ErrNetwork), errors.Is(err, client.ErrRateLimited), errors.Is(err, context.DeadlineExceeded):
+		return ExitNetwork
+	}
+
+	return ExitError
+}
+
+// onUsageError shows the help, and returns the error with ExitUsage.
+func onUsageError(c *cli.Context, err error, isSubcommand bool) error {
+	if isSubcommand {
+		cli.ShowCommandHelp(c, c.Command.Name)
+	} else {
+		cli.ShowAppHelp(c)
+	}
+
+	return withExitCode(ExitUsage, err)
+}
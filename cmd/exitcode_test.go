@@ -0,0 +1,98 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+
+	"github.com/AlecAivazis/survey/v2/terminal"
+	"github.com/blacknon/snipt/client"
+	"github.com/blacknon/snipt/finder"
+	"github.com/urfave/cli/v2"
+)
+
+func TestGetExitCode(t *testing.T) {
+	// *exec.ExitError of $EDITOR or select command
+	execErr := exec.Command("sh", "-c", "exit 3").Run()
+	if _, ok := execErr.(*exec.ExitError); !ok {
+		t.Fatalf("exec error = %v, want *exec.ExitError", execErr)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"ok", nil, ExitOK},
+		{"error", errors.New("error"), ExitError},
+		{"usage", newUsageError("missing argument"), ExitUsage},
+		{"not found", newNotFoundError("file not found"), ExitNotFound},
+		{"exit code is preferred", withExitCode(ExitTrouble, client.ErrAuth), ExitTrouble},
+		{"different", errDifferent, ExitDifferent},
+		{"auth", fmt.Errorf("get: %w", client.ErrAuth), ExitAuth},
+		{"snippet not found", fmt.Errorf("get: %w", client.ErrNotFound), ExitNotFound},
+		{"network", client.ErrNetwork, ExitNetwork},
+		{"rate limited", client.ErrRateLimited, ExitNetwork},
+		{"timeout", context.DeadlineExceeded, ExitNetwork},
+		{"no snippet matched", errNoSnippetMatched, ExitNotFound},
+		{"multiple snippets matched", errMultipleSnippetMatched, ExitUsage},
+		{"selection cancelled", fmt.Errorf("%w: exit status 130", finder.ErrCancelled), ExitCancelled},
+		{"interrupted", terminal.InterruptErr, ExitCancelled},
+		{"cli exit", cli.Exit("No help topic for 'foo'", 3), ExitUsage},
+		{"editor failed", fmt.Errorf("editor: %w", execErr), ExitError},
+		{"editor failed without wrap", execErr, ExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getExitCode(context.Background(), tt.err); got != tt.want {
+				t.Errorf("getExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	// canceled by signal
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := getExitCode(ctx, errors.New("error")); got != ExitCancelled {
+		t.Errorf("getExitCode() of canceled context = %d, want %d", got, ExitCancelled)
+	}
+
+	// partial failure
+	partialFailure = errors.New("github.com: network error")
+	defer func() { partialFailure = nil }()
+	if got := getExitCode(context.Background(), nil); got != ExitPartial {
+		t.Errorf("getExitCode() of partial failure = %d, want %d", got, ExitPartial)
+	}
+}
+
+func TestFilterExitCode(t *testing.T) {
+	tests := []struct {
+		code int
+		want int
+	}{
+		{130, ExitCancelled},
+		{1, ExitCancelled},
+		{2, ExitError},
+		{127, ExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.code), func(t *testing.T) {
+			_, err := filter(fmt.Sprintf("sh -c 'exit %d'", tt.code), []string{}, "a\nb\n")
+			if err == nil {
+				t.Fatal("filter() succeeded")
+			}
+
+			if got := getExitCode(context.Background(), err); got != tt.want {
+				t.Errorf("getExitCode(%v) = %d, want %d", err, got, tt.want)
+			}
+		})
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/blacknon/snipt/client"
 	"github.com/urfave/cli/v2"
@@ -16,10 +15,11 @@ import (
 
 // CmdGet
 var CmdGet = cli.Command{
-	Name:   "get",
-	Usage:  "get remote snippet data.",
-	Action: cmdActionGet,
-	Flags: []cli.Flag{
+	Name:      "get",
+	Usage:     "get remote snippet data.",
+	Action:    cmdActionGet,
+	ArgsUsage: "[URL...]",
+	Flags: append([]cli.Flag{
 		// -o PATH
 		CommonFlagOutput,
 
@@ -35,42 +35,46 @@ var CmdGet = cli.Command{
 			Aliases: []string{"r"},
 			Usage:   "printout to stdout from snippet.",
 		},
-	},
+
+		// --revision REV
+		&cli.StringFlag{
+			Name:  "revision",
+			Usage: "get snippet at `REV`. the revision can be the prefix shown by the history subcommand.",
+		},
+	}, CommonFlagsSelect...),
 }
 
 // actionList is the function that defines the processing of the lists subcommand.
 func cmdActionGet(c *cli.Context) (err error) {
 	// Get **config data** and **client.Client**
-	cf := c.String("config")
-	conf, cl, err := clinetInit(cf)
+	conf, cl, err := clinetInit(c)
 	if err != nil {
 		return
 	}
 
 	// Get List
-	list := cl.List(c.Bool("file"), c.Bool("secret"))
-
-	// Create list
-	var filterText string
-	for _, l := range list {
-		t := fmt.Sprintln(l.URL, l.Platform, l.Title)
-		filterText += t
+	list, err := getSnippetList(c.Context, &cl, c.Bool("file"), c.Bool("secret"))
+	if err != nil {
+		return
 	}
 
-	// Run filter command
-	text, err := filter(conf.General.SelectCmd, []string{}, filterText)
+	// Select snippet
+	urlList, err := selectSnippetURL(c, conf.General.SelectCmd, &cl, list, c.Args().Slice())
+	if err != nil {
+		return
+	}
 
 	// get snippet files
 	files := []client.SnippetFileData{}
-	for _, t := range text {
-		// generate url as search key value.
-		splitText := strings.Split(t, " ")
-		url := splitText[0]
-
+	for _, url := range urlList {
 		// Get SnippetData
-		snippet, err := cl.Get(url)
+		var snippet client.SnippetData
+		if revision := c.String("revision"); revision != "" {
+			snippet, err = cl.GetRevision(c.Context, url, revision)
+		} else {
+			snippet, err = cl.Get(c.Context, url)
+		}
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
 			return err
 		}
 
@@ -0,0 +1,228 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/blacknon/snipt/client"
+	"github.com/urfave/cli/v2"
+)
+
+// grepConcurrency is the number of snippets fetched at the same time.
+const grepConcurrency = 8
+
+// CmdGrep
+var CmdGrep = cli.Command{
+	Name:      "grep",
+	Aliases:   []string{"search"},
+	Usage:     "search remote snippet contents with regular expression.",
+	Action:    cmdActionGrep,
+	ArgsUsage: "PATTERN",
+	Flags: []cli.Flag{
+		// -i
+		&cli.BoolFlag{
+			Name:    "ignore-case",
+			Aliases: []string{"i"},
+			Usage:   "ignore case distinctions in PATTERN.",
+		},
+
+		// -l
+		&cli.BoolFlag{
+			Name:    "files-with-matches",
+			Aliases: []string{"l"},
+			Usage:   "print only the URL of matched snippets. it can be passed to get, edit and delete as it is.",
+		},
+
+		// -n
+		&cli.BoolFlag{
+			Name:    "line-number",
+			Aliases: []string{"n"},
+			Usage:   "print line number with output lines.",
+		},
+
+		// -C NUM
+		&cli.IntFlag{
+			Name:    "context",
+			Aliases: []string{"C"},
+			Usage:   "print `NUM` lines of output context.",
+		},
+
+		// -s
+		CommonFlagViewSecret,
+
+		// --platform PLATFORM
+		&cli.StringFlag{
+			Name:  "platform",
+			Usage: "narrow down the snippets to the `PLATFORM` containing this string. ex) gist.github.com:user",
+		},
+	},
+}
+
+func cmdActionGrep(c *cli.Context) (err error) {
+	// check args count
+	if c.NArg() != 1 {
+		err = newUsageError("specify one pattern")
+		c.App.OnUsageError(c, err, true)
+		return
+	}
+
+	// compile pattern
+	pattern := c.Args().First()
+	if c.Bool("ignore-case") {
+		pattern = "(?i)" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return withExitCode(ExitUsage, err)
+	}
+
+	// Get **config data** and **client.Client**
+	_, cl, err := clinetInit(c)
+	if err != nil {
+		return
+	}
+
+	// Get List
+	list, err := getSnippetList(c.Context, &cl, false, c.Bool("secret"))
+	if err != nil {
+		return
+	}
+	if platform := c.String("platform"); platform != "" {
+		list = list.Where(func(s *client.SnippetListData) bool {
+			return strings.Contains(s.Platform, platform)
+		})
+	}
+
+	// search snippets concurrently
+	results := make([]string, len(list))
+
+	var wg sync.WaitGroup
+	var m sync.Mutex
+	var failed int
+	var getErr error
+	sem := make(chan struct{}, grepConcurrency)
+	for i, l := range list {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			snippet, gerr := cl.Get(c.Context, url)
+			if gerr != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s: %s\n", url, gerr)
+
+				m.Lock()
+				failed++
+				getErr = gerr
+				m.Unlock()
+				return
+			}
+
+			results[i] = grepSnippet(re, snippet, c.Bool("files-with-matches"), c.Bool("line-number"), c.Int("context"))
+		}(i, l.URL)
+	}
+	wg.Wait()
+
+	// output in list order
+	for _, r := range results {
+		fmt.Print(r)
+	}
+
+	if failed > 0 {
+		err = fmt.Errorf("%d of %d snippets could not be searched", failed, len(list))
+
+		// all snippets failed: exit with the code of the error. ex) ExitNetwork
+		code := ExitPartial
+		if failed == len(list) {
+			code = getExitCode(c.Context, getErr)
+		}
+
+		return withExitCode(code, err)
+	}
+
+	return
+}
+
+// grepSnippet returns the matched lines of snippet files.
+func grepSnippet(re *regexp.Regexp, snippet client.SnippetData, isFileOnly, isLineNumber bool, context int) string {
+	var buf bytes.Buffer
+
+	for _, f := range snippet.Files {
+		name := f.Filter
+		if name == "" {
+			name = snippet.URL + "/" + f.Path
+		}
+
+		lines := strings.Split(strings.TrimSuffix(string(f.Contents), "\n"), "\n")
+
+		// find matched lines
+		matched := []int{}
+		for i, line := range lines {
+			if re.MatchString(line) {
+				matched = append(matched, i)
+			}
+		}
+
+		if len(matched) == 0 {
+			continue
+		}
+
+		// snippet url is printed once
+		if isFileOnly {
+			fmt.Fprintln(&buf, snippet.URL)
+			break
+		}
+
+		// output matched lines with context
+		last := -1
+		for _, m := range matched {
+			start := m - context
+			if start <= last {
+				start = last + 1
+			}
+			if start < 0 {
+				start = 0
+			}
+
+			if context > 0 && last >= 0 && start > last+1 {
+				fmt.Fprintln(&buf, "--")
+			}
+
+			end := m + context
+			if end >= len(lines) {
+				end = len(lines) - 1
+			}
+
+			for i := start; i <= end; i++ {
+				// separator is `:` for matched line, `-` for context line.
+				sep := ":"
+				if !re.MatchString(lines[i]) {
+					sep = "-"
+				}
+
+				if isLineNumber {
+					fmt.Fprintf(&buf, "%s%s%d%s %s\n", name, sep, i+1, sep, lines[i])
+				} else {
+					fmt.Fprintf(&buf, "%s%s %s\n", name, sep, lines[i])
+				}
+			}
+
+			if end > last {
+				last = end
+			}
+		}
+	}
+
+	return buf.String()
+}
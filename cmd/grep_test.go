@@ -0,0 +1,56 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blacknon/snipt/client"
+)
+
+func TestCmdGrepExitCode(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	conf := newTestLocalConfig(t, dir, false)
+
+	l := &client.LocalClient{}
+	if err := l.Init(ctx, dir, false); err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, path := range []string{"a.sh", "b.sh"} {
+		s, err := l.Create(ctx, client.SnippetData{Files: []client.SnippetFileData{{Path: path, Contents: []byte("echo " + path + "\n")}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, s.Id)
+	}
+
+	// invalid pattern
+	err := App.RunContext(ctx, []string{"snipt", "--config", conf, "grep", "("})
+	if code := getExitCode(ctx, err); code != ExitUsage {
+		t.Errorf("grep with invalid pattern: getExitCode(%v) = %d, want %d", err, code, ExitUsage)
+	}
+
+	// all snippets are searched
+	err = App.RunContext(ctx, []string{"snipt", "--config", conf, "grep", "-s", "echo"})
+	if code := getExitCode(ctx, err); code != ExitOK {
+		t.Errorf("grep: getExitCode(%v) = %d, want %d", err, code, ExitOK)
+	}
+
+	// a snippet can not be read
+	if err = os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, ids[0], "broken.sh")); err != nil {
+		t.Fatal(err)
+	}
+
+	err = App.RunContext(ctx, []string{"snipt", "--config", conf, "grep", "-s", "echo"})
+	if code := getExitCode(ctx, err); code != ExitPartial {
+		t.Errorf("grep with unreadable snippet: getExitCode(%v) = %d, want %d", err, code, ExitPartial)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/blacknon/snipt/client"
+	"github.com/urfave/cli/v2"
+)
+
+// CmdHistory
+var CmdHistory = cli.Command{
+	Name:      "history",
+	Usage:     "list revisions of remote snippet. supported by gist, gitlab and local (git = true).",
+	Action:    cmdActionHistory,
+	ArgsUsage: "[URL]",
+	Flags: append([]cli.Flag{
+		// -s
+		CommonFlagViewSecret,
+	}, CommonFlagsSelect...),
+}
+
+// CmdRestore
+var CmdRestore = cli.Command{
+	Name:      "restore",
+	Usage:     "restore remote snippet to the revision, as a new update. files added after the revision are deleted.",
+	Action:    cmdActionRestore,
+	ArgsUsage: "REVISION [URL]",
+	Flags: append([]cli.Flag{
+		// -s
+		CommonFlagViewSecret,
+	}, CommonFlagsSelect...),
+}
+
+func cmdActionHistory(c *cli.Context) (err error) {
+	_, cl, url, err := getSnippetTarget(c, c.Args().Slice())
+	if err != nil {
+		return
+	}
+
+	revisions, err := cl.ListRevisions(c.Context, url)
+	if err != nil {
+		return
+	}
+
+	for _, r := range revisions {
+		fmt.Printf("%s %s %s %s\n", shortRevision(r.Revision), r.CreatedAt.Local().Format("2006/01/02 15:04:05"), r.Author, r.Message)
+	}
+
+	return
+}
+
+func cmdActionRestore(c *cli.Context) (err error) {
+	// check args count
+	if c.NArg() == 0 {
+		err = newUsageError("no revision")
+		c.App.OnUsageError(c, err, true)
+		return
+	}
+
+	revision := c.Args().First()
+	_, cl, url, err := getSnippetTarget(c, c.Args().Tail())
+	if err != nil {
+		return
+	}
+
+	// Get SnippetData of the revision
+	old, err := cl.GetRevision(c.Context, url, revision)
+	if err != nil {
+		return
+	}
+
+	// Get current SnippetData
+	snippetData, err := cl.Get(c.Context, url)
+	if err != nil {
+		return
+	}
+
+	// replace files, and delete the files added after the revision. title, description and visibility are kept.
+	snippetData.Files = getRestoreFiles(snippetData.Files, old.Files)
+
+	// update
+	snippets, err := cl.Update(c.Context, url, snippetData)
+	if err != nil {
+		return
+	}
+
+	for _, s := range snippets {
+		fmt.Printf("Snippet Update: %s\n", s.URL)
+	}
+
+	return
+}
+
+// getRestoreFiles returns the files to update the snippet of currentFiles to revisionFiles.
+// The files only in currentFiles are deleted.
+func getRestoreFiles(currentFiles, revisionFiles []client.SnippetFileData) (files []client.SnippetFileData) {
+	isRevisionFile := map[string]bool{}
+	for _, f := range revisionFiles {
+		isRevisionFile[f.Path] = true
+		files = append(files, client.SnippetFileData{Path: f.Path, Contents: f.Contents})
+	}
+
+	for _, f := range currentFiles {
+		if !isRevisionFile[f.Path] {
+			files = append(files, client.SnippetFileData{Path: f.Path, Deleted: true})
+		}
+	}
+
+	return
+}
+
+// shortRevision returns the first 8 characters of the revision.
+func shortRevision(revision string) string {
+	if len(revision) > 8 {
+		return revision[:8]
+	}
+
+	return revision
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/blacknon/snipt/client"
+)
+
+func TestGetRestoreFiles(t *testing.T) {
+	current := []client.SnippetFileData{
+		{Path: "a.sh", Contents: []byte("new a")},
+		{Path: "b.sh", Contents: []byte("b")},
+	}
+	revision := []client.SnippetFileData{
+		{Path: "a.sh", Contents: []byte("old a")},
+		{Path: "c.sh", Contents: []byte("c")},
+	}
+
+	var got []string
+	for _, f := range getRestoreFiles(current, revision) {
+		got = append(got, fmt.Sprintf("%s=%s/%v", f.Path, f.Contents, f.Deleted))
+	}
+
+	if want := "a.sh=old a/false,c.sh=c/false,b.sh=/true"; strings.Join(got, ",") != want {
+		t.Errorf("getRestoreFiles() = %s, want %s", strings.Join(got, ","), want)
+	}
+}
+
+func TestCmdRestore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	conf := newTestLocalConfig(t, dir, true)
+
+	l := &client.LocalClient{}
+	if err := l.Init(ctx, dir, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// revision 1: a.sh
+	created, err := l.Create(ctx, client.SnippetData{Title: "title", Files: []client.SnippetFileData{{Path: "a.sh", Contents: []byte("old a\n")}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// revision 2: a.sh is changed, and b.sh is added after revision 1
+	_, err = l.Update(ctx, created.Id, client.SnippetData{Title: "title", Files: []client.SnippetFileData{
+		{Path: "a.sh", Contents: []byte("new a\n")},
+		{Path: "b.sh", Contents: []byte("b\n")},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := l.ListRevisions(ctx, created.Id)
+	if err != nil || len(revisions) != 2 {
+		t.Fatalf("ListRevisions() = %v, %v", revisions, err)
+	}
+
+	err = App.RunContext(ctx, []string{"snipt", "--config", conf, "restore", "-s", shortRevision(revisions[1].Revision), created.URL})
+	if err != nil {
+		t.Fatalf("restore error = %v", err)
+	}
+
+	snippet, err := l.Get(ctx, created.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snippet.Files) != 1 || snippet.Files[0].Path != "a.sh" || string(snippet.Files[0].Contents) != "old a\n" {
+		t.Errorf("files after restore = %+v", snippet.Files)
+	}
+
+	// restore is committed as a new revision
+	if revisions, _ = l.ListRevisions(ctx, created.Id); len(revisions) != 3 {
+		t.Errorf("ListRevisions() after restore = %d revisions, want 3", len(revisions))
+	}
+}
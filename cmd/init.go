@@ -5,15 +5,25 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/blacknon/snipt/client"
 	"github.com/blacknon/snipt/config"
+	"github.com/urfave/cli/v2"
 )
 
 var (
 	// config file
 	configFileName = "config.toml"
+
+	// cache directory
+	cacheDirName = "cache"
 )
 
 // loadConfig
@@ -35,28 +45,94 @@ func loadConfig(configFile string) (configData config.Config, err error) {
 }
 
 // clientInit
-func clinetInit(c string) (conf config.Config, cl client.Client, err error) {
-	conf, err = loadConfig(getFullPath(c))
+func clinetInit(c *cli.Context) (conf config.Config, cl client.Client, err error) {
+	conf, err = loadConfig(getFullPath(c.String("config")))
+	if err != nil {
+		return conf, cl, err
+	}
+
+	// Create cache
+	dir, err := config.GetDefaultConfigDir()
 	if err != nil {
 		return conf, cl, err
 	}
 
+	ttl, err := conf.General.GetCacheTTL()
+	if err != nil {
+		return conf, cl, err
+	}
+
+	cache := &client.Cache{
+		Dir:     filepath.Join(dir, cacheDirName),
+		TTL:     ttl,
+		Refresh: c.Bool("refresh"),
+	}
+
 	// Create client
-	cl = client.Client{}
-	cl.Init(conf)
+	cl = client.Client{Cache: cache, Strict: c.Bool("strict"), Timeout: c.Duration("timeout"), Verbose: getVerboseWriter(c)}
+	if ierr := cl.Init(c.Context, conf); ierr != nil {
+		if cl.Strict {
+			return conf, cl, ierr
+		}
+
+		// continue with the healthy accounts
+		for _, a := range cl.Accounts() {
+			if a.Err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s. skipped.\n", a.Err)
+			}
+		}
+		partialFailure = ierr
+	}
 
-	return conf, cl, err
+	return conf, cl, nil
 }
 
-// getPathList
+// getSnippetList gets the snippet list of all platforms.
+// The errors of the platforms are printed as warning, and the snippets of the other platforms are used.
+// If no snippet is got, the error is returned.
+func getSnippetList(ctx context.Context, cl *client.Client, isFile, isSecret bool) (list client.SnippetList, err error) {
+	list, err = cl.List(ctx, isFile, isSecret)
+	if err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	var listErr client.ListError
+	if !errors.As(err, &listErr) || len(list) == 0 {
+		return
+	}
+
+	platforms := []string{}
+	for platform := range listErr {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+
+	for _, platform := range platforms {
+		fmt.Fprintf(os.Stderr, "Error: %s: %s\n", platform, listErr[platform])
+	}
+	partialFailure = err
+
+	return list, nil
+}
+
+// getVerboseWriter returns the writer of verbose log. If --verbose is not set, it returns nil.
+func getVerboseWriter(c *cli.Context) io.Writer {
+	if c.Bool("verbose") {
+		return os.Stderr
+	}
+
+	return nil
+}
+
+// getPathList returns the full paths of args. If a file does not exist, it returns the not found error.
 func getPathList(args []string) (pathList []string, err error) {
 	for _, a := range args {
 		p := getFullPath(a)
-		if isExist(p) {
-			pathList = append(pathList, p)
-		} else {
-			return
+		if !isExist(p) {
+			return nil, newNotFoundError("no such file: %s", a)
 		}
+
+		pathList = append(pathList, p)
 	}
 
 	return pathList, err
@@ -0,0 +1,52 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// newTestLocalConfig creates the config file of the local snippets in dir, and returns the path.
+// HOME is changed, so that the cache and the config of the user are not used.
+func newTestLocalConfig(t *testing.T, dir string, git bool) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+
+	conf := filepath.Join(home, "config.toml")
+	data := "[General]\n  selectcmd = \"builtin\"\n\n[[Local]]\n  path = \"" + filepath.ToSlash(dir) + "\"\n  git = " + strconv.FormatBool(git) + "\n"
+	if err := os.WriteFile(conf, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	return conf
+}
+
+func TestGetPathList(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.sh")
+	if err := os.WriteFile(a, []byte("echo a\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	pathList, err := getPathList([]string{a})
+	if err != nil || len(pathList) != 1 || pathList[0] != a {
+		t.Errorf("getPathList() = %v, %v", pathList, err)
+	}
+
+	// the file not found is not skipped
+	missing := filepath.Join(dir, "missing.sh")
+	pathList, err = getPathList([]string{a, missing, a})
+	if err == nil || pathList != nil {
+		t.Fatalf("getPathList() with missing file = %v, %v", pathList, err)
+	}
+	if code := getExitCode(context.Background(), err); code != ExitNotFound {
+		t.Errorf("getExitCode() = %d, want %d", code, ExitNotFound)
+	}
+}
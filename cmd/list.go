@@ -5,9 +5,15 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"text/template"
 
-	// "github.com/olekukonko/tablewriter"
+	"github.com/blacknon/snipt/client"
 	"github.com/urfave/cli/v2"
 )
 
@@ -17,12 +23,18 @@ var CmdList = cli.Command{
 	Usage:  "list all snippet.",
 	Action: cmdActionList,
 	Flags: []cli.Flag{
-		// -T
-		// &cli.BoolFlag{
-		// 	Name:    "table",
-		// 	Aliases: []string{"T"},
-		// 	Usage:   "output in markdown table format",
-		// },
+		// -F FORMAT
+		&cli.StringFlag{
+			Name:    "format",
+			Aliases: []string{"F"},
+			Usage:   "output in `FORMAT`. (json|ndjson|tsv|table|template)",
+		},
+
+		// --template TEMPLATE
+		&cli.StringFlag{
+			Name:  "template",
+			Usage: "Go text/template `TEMPLATE` executed for each snippet. used with `--format template`. ex) '{{.URL}} {{.Title}}'",
+		},
 
 		// -f
 		CommonFlagSnippetFile,
@@ -32,28 +44,136 @@ var CmdList = cli.Command{
 	},
 }
 
+// listOutputData is the schema of `list --format json|ndjson`.
+// Keys are kept stable so that other tools can depend on them.
+type listOutputData struct {
+	Platform   string `json:"platform"`
+	Id         string `json:"id"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	RawURL     string `json:"raw_url"`
+	Visibility string `json:"visibility"`
+}
+
 // actionList is the function that defines the processing of the lists subcommand.
 func cmdActionList(c *cli.Context) (err error) {
+	// check format
+	format := c.String("format")
+	if format == "" && c.IsSet("template") {
+		format = "template"
+	}
+
+	switch format {
+	case "", "json", "ndjson", "tsv", "table", "template":
+	default:
+		err = newUsageError("unknown format: %s", format)
+		c.App.OnUsageError(c, err, true)
+		return
+	}
+
+	if format == "template" && c.String("template") == "" {
+		err = newUsageError("--format template requires --template")
+		c.App.OnUsageError(c, err, true)
+		return
+	}
+
 	// Get **config data** and **client.Client**
-	cf := c.String("config")
-	_, cl, err := clinetInit(cf)
+	_, cl, err := clinetInit(c)
 	if err != nil {
 		return
 	}
 
 	// Get List
-	list := cl.List(c.Bool("file"), c.Bool("secret"))
+	list, err := getSnippetList(c.Context, &cl, c.Bool("file"), c.Bool("secret"))
+	if err != nil {
+		return
+	}
 
 	// Output list
-	for _, l := range list {
-		u := l.URL
-		if c.Bool("file") {
-			u = l.RawURL
+	return outputList(os.Stdout, format, c.String("template"), c.Bool("file"), list)
+}
+
+// outputList
+func outputList(w io.Writer, format, tmpl string, isFile bool, list client.SnippetList) (err error) {
+	switch format {
+	case "json":
+		data := []listOutputData{}
+		for _, l := range list {
+			data = append(data, createListOutputData(l))
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(data)
+
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		for _, l := range list {
+			if err = enc.Encode(createListOutputData(l)); err != nil {
+				return
+			}
+		}
+
+	case "tsv":
+		for _, l := range list {
+			d := createListOutputData(l)
+			fields := []string{d.Platform, d.Id, d.Title, d.URL, d.RawURL, d.Visibility}
+			for i, f := range fields {
+				fields[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(f)
+			}
+
+			fmt.Fprintln(w, strings.Join(fields, "\t"))
 		}
 
-		t := fmt.Sprintln(u, l.Visibility+": "+l.Title)
-		fmt.Print(t)
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "PLATFORM\tVISIBILITY\tURL\tTITLE")
+		for _, l := range list {
+			u := l.URL
+			if isFile {
+				u = l.RawURL
+			}
+
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", l.Platform, l.Visibility, u, l.Title)
+		}
+		err = tw.Flush()
+
+	case "template":
+		t, perr := template.New("list").Parse(tmpl)
+		if perr != nil {
+			return perr
+		}
+
+		for _, l := range list {
+			if err = t.Execute(w, l); err != nil {
+				return
+			}
+			fmt.Fprintln(w)
+		}
+
+	default:
+		for _, l := range list {
+			u := l.URL
+			if isFile {
+				u = l.RawURL
+			}
+
+			t := fmt.Sprintln(u, l.Visibility+": "+l.Title)
+			fmt.Fprint(w, t)
+		}
 	}
 
 	return
 }
+
+// createListOutputData
+func createListOutputData(l *client.SnippetListData) listOutputData {
+	return listOutputData{
+		Platform:   l.Platform,
+		Id:         l.Id,
+		Title:      l.Title,
+		URL:        l.URL,
+		RawURL:     l.RawURL,
+		Visibility: l.Visibility,
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TestCmdListUsageError(t *testing.T) {
+	tests := [][]string{
+		{"snipt", "list", "--format", "template"},
+		{"snipt", "list", "--format", "xml"},
+	}
+
+	for _, args := range tests {
+		app := &cli.App{
+			Commands:       []*cli.Command{&CmdList},
+			OnUsageError:   onUsageError,
+			ExitErrHandler: func(c *cli.Context, err error) {},
+			Writer:         io.Discard,
+		}
+
+		// the format is checked before the config is loaded
+		err := app.Run(args)
+		if code := getExitCode(context.Background(), err); code != ExitUsage {
+			t.Errorf("%v: getExitCode(%v) = %d, want %d", args, err, code, ExitUsage)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/blacknon/snipt/client"
+	"github.com/blacknon/snipt/finder"
+	"github.com/urfave/cli/v2"
+)
+
+// previewCacheEnv is the environment variable of the preview cache directory shared in the selection session.
+const previewCacheEnv = "SNIPT_PREVIEW_CACHE"
+
+// CmdPreview is used by the select command (fzf) to show the snippet contents.
+var CmdPreview = cli.Command{
+	Name:      "preview",
+	Usage:     "printout remote snippet contents for the preview of select command.",
+	Action:    cmdActionPreview,
+	ArgsUsage: "URL",
+	Hidden:    true,
+}
+
+func cmdActionPreview(c *cli.Context) (err error) {
+	if c.NArg() == 0 {
+		return
+	}
+	url := c.Args().First()
+
+	// read from session cache
+	cacheFile := ""
+	if dir := os.Getenv(previewCacheEnv); dir != "" {
+		cacheFile = filepath.Join(dir, getPreviewCacheName(url))
+		if data, rerr := read(cacheFile); rerr == nil {
+			fmt.Print(string(data))
+			return
+		}
+	}
+
+	// Get **config data** and **client.Client**
+	_, cl, err := clinetInit(c)
+	if err != nil {
+		return
+	}
+
+	// Get List
+	list, err := getSnippetList(c.Context, &cl, true, true)
+	if err != nil {
+		return
+	}
+
+	// snippet url is not in the file list, so get it by the url of the file.
+	getURL := url
+	for _, l := range list {
+		if strings.HasPrefix(l.URL, url+"/") {
+			getURL = l.URL
+			break
+		}
+	}
+
+	snippet, err := cl.Get(c.Context, getURL)
+	if err != nil {
+		return
+	}
+
+	text := createPreviewText(url, snippet)
+	if cacheFile != "" {
+		os.WriteFile(cacheFile, []byte(text), 0600)
+	}
+
+	fmt.Print(text)
+
+	return
+}
+
+// snippetPreviewer provides the preview of snippets to the select command.
+type snippetPreviewer struct {
+	ctx        context.Context
+	cl         *client.Client
+	configFile string
+
+	m     sync.Mutex
+	cache map[string]string
+}
+
+// Preview returns the preview text of the select line. used by the built-in finder.
+func (p *snippetPreviewer) Preview(line string) string {
+	url := strings.Split(line, " ")[0]
+
+	p.m.Lock()
+	text, ok := p.cache[url]
+	p.m.Unlock()
+	if ok {
+		return text
+	}
+
+	snippet, err := p.cl.Get(p.ctx, url)
+	if err != nil {
+		return err.Error()
+	}
+
+	text = createPreviewText(url, snippet)
+
+	p.m.Lock()
+	p.cache[url] = text
+	p.m.Unlock()
+
+	return text
+}
+
+// filter runs the select command with the preview of snippets.
+// The built-in finder shows the preview pane, and fzf is given `--preview` running `snipt preview`.
+func (p *snippetPreviewer) filter(selectCmd, filterText string) (filteredData []string, err error) {
+	if isBuiltinSelectCmd(selectCmd) {
+		if filterText == "" {
+			return
+		}
+
+		p.cache = map[string]string{}
+		f := &finder.Finder{
+			Items:   strings.Split(strings.TrimSuffix(filterText, "\n"), "\n"),
+			Prompt:  "> ",
+			Multi:   true,
+			Preview: p.Preview,
+		}
+
+		return f.Run()
+	}
+
+	// other than fzf or on windows, run without preview
+	fields := strings.Fields(selectCmd)
+	if filepath.Base(fields[0]) != "fzf" || runtime.GOOS == "windows" {
+		return filter(selectCmd, []string{}, filterText)
+	}
+
+	// create preview command
+	exe, err := os.Executable()
+	if err != nil {
+		return
+	}
+
+	previewCmd := shellQuote(exe)
+	if p.configFile != "" {
+		previewCmd += " --config " + shellQuote(getFullPath(p.configFile))
+	}
+	previewCmd += " preview {1}"
+
+	// create session cache directory
+	dir, err := os.MkdirTemp("", "snipt_preview_")
+	if err != nil {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv(previewCacheEnv, dir)
+	defer os.Unsetenv(previewCacheEnv)
+
+	return filter(selectCmd, []string{"--preview", shellQuote(previewCmd)}, filterText)
+}
+
+// createPreviewText
+func createPreviewText(url string, snippet client.SnippetData) (text string) {
+	for _, f := range snippet.Files {
+		// show only the file when url is the file url
+		if f.Filter != url && strings.HasPrefix(url, snippet.URL+"/") {
+			continue
+		}
+
+		text += fmt.Sprintf("==> %s <==\n", f.Path)
+		text += string(f.Contents)
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		text += "\n"
+	}
+
+	return
+}
+
+// getPreviewCacheName
+func getPreviewCacheName(url string) string {
+	sum := sha1.Sum([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+// shellQuote quotes s for `sh -c`.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
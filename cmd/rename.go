@@ -3,3 +3,92 @@
 // that can be found in the LICENSE file.
 
 package cmd
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+// CmdRename
+var CmdRename = cli.Command{
+	Name:      "rename",
+	Usage:     "rename remote snippet file.",
+	Action:    cmdActionRename,
+	ArgsUsage: "NEW_FILENAME",
+	Flags: append([]cli.Flag{
+		// -s
+		CommonFlagViewSecret,
+	}, CommonFlagsSelect...),
+}
+
+func cmdActionRename(c *cli.Context) (err error) {
+	// check args count
+	if c.NArg() != 1 {
+		err = newUsageError("specify one new filename")
+		c.App.OnUsageError(c, err, true)
+		return
+	}
+
+	// get new filename
+	newName := c.Args().First()
+
+	// Get **config data** and **client.Client**
+	conf, cl, err := clinetInit(c)
+	if err != nil {
+		return
+	}
+
+	// Get List
+	list, err := getSnippetList(c.Context, &cl, true, c.Bool("secret"))
+	if err != nil {
+		return
+	}
+
+	// Select target file
+	urlList, err := selectSnippetURL(c, conf.General.SelectCmd, &cl, list, nil)
+	if err != nil {
+		return
+	}
+
+	if len(urlList) != 1 {
+		return newUsageError("select one snippet file to rename")
+	}
+	url := urlList[0]
+
+	// Get SnippetData
+	snippetData, err := cl.Get(c.Context, url)
+	if err != nil {
+		return
+	}
+
+	// rename file
+	isRenamed := false
+	for i, f := range snippetData.Files {
+		if f.Path == newName {
+			return fmt.Errorf("file %s already exists in %s", newName, snippetData.URL)
+		}
+
+		if url == f.Filter {
+			snippetData.Files[i].PreviousPath = f.Path
+			snippetData.Files[i].Path = newName
+			isRenamed = true
+		}
+	}
+
+	if !isRenamed {
+		return newNotFoundError("snippet file not found: %s", url)
+	}
+
+	// update
+	snippets, err := cl.Update(c.Context, url, snippetData)
+	if err != nil {
+		return
+	}
+
+	for _, s := range snippets {
+		fmt.Printf("Snippet Update: %s\n", s.URL)
+	}
+
+	return
+}
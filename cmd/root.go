@@ -5,8 +5,11 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/urfave/cli/v2"
 )
@@ -37,21 +40,45 @@ var App = &cli.App{
 		// edit subcommand
 		&CmdEdit,
 
+		// diff subcommand
+		&CmdDiff,
+
+		// grep subcommand
+		&CmdGrep,
+
 		// delete subcommand
 		&CmdDelete,
 
 		// add subcommand
+		&CmdAdd,
+
+		// rename subcommand
+		&CmdRename,
 
 		// comment subcommand
+		&CmdComment,
 
 		// copy subcommand
+		&CmdCopy,
+
+		// history subcommand
+		&CmdHistory,
+
+		// restore subcommand
+		&CmdRestore,
+
+		// accounts subcommand
+		&CmdAccounts,
+
+		// preview subcommand (hidden)
+		&CmdPreview,
 	},
 
 	// Output usages and error messages
-	OnUsageError: func(c *cli.Context, err error, isSubcommand bool) error {
-		cli.ShowAppHelp(c)
-		return err
-	},
+	OnUsageError: onUsageError,
+
+	// exit code is decided by Execute
+	ExitErrHandler: func(c *cli.Context, err error) {},
 }
 
 // CommonFlags
@@ -62,6 +89,30 @@ var commonFlags = []cli.Flag{
 		Aliases: []string{"c"},
 		Usage:   "load configuration from `FILE`",
 	},
+
+	// refresh option
+	&cli.BoolFlag{
+		Name:  "refresh",
+		Usage: "ignore the local cache and get all snippets from remote platforms.",
+	},
+
+	// timeout option
+	&cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "timeout of each access to the remote platforms, ex) 30s, 1m. 0 means no timeout.",
+	},
+
+	// strict option
+	&cli.BoolFlag{
+		Name:  "strict",
+		Usage: "initialize all accounts at start, and abort if any account fails to initialize.",
+	},
+
+	// verbose option
+	&cli.BoolFlag{
+		Name:  "verbose",
+		Usage: "print each request to the remote platforms, its retries and the remaining rate limit to stderr.",
+	},
 }
 
 // CommonFlagOutput ... -o, --output
@@ -101,8 +152,38 @@ var CommonFlagSelecterVisibility = &cli.BoolFlag{
 
 // Execute
 func Execute() {
+	// cancel the requests in progress by Ctrl-C (or SIGTERM)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// the second signal terminates snipt immediately
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	// usage errors of subcommands
+	setOnUsageError(App.Commands)
+
 	// execute command
-	if err := App.Run(os.Args); err != nil {
+	err := App.RunContext(ctx, os.Args)
+	if err != nil && err != errDifferent {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 	}
+
+	code := getExitCode(ctx, err)
+	stop()
+
+	os.Exit(code)
+}
+
+// setOnUsageError
+func setOnUsageError(commands []*cli.Command) {
+	for _, command := range commands {
+		if command.OnUsageError == nil {
+			command.OnUsageError = onUsageError
+		}
+
+		setOnUsageError(command.Subcommands)
+	}
 }
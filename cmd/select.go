@@ -0,0 +1,272 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/blacknon/snipt/client"
+	"github.com/blacknon/snipt/config"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// errNoSnippetMatched is returned when no snippet matches the --id/--query flags.
+	errNoSnippetMatched = errors.New("no snippet matched")
+
+	// errMultipleSnippetMatched is returned when multiple snippets match the --id/--query flags without --all.
+	errMultipleSnippetMatched = errors.New("multiple snippets matched. use --all to select all of them")
+)
+
+// CommonFlagsSelect ... --url, --id, --platform, --query, --all
+var CommonFlagsSelect = []cli.Flag{
+	// -u URL
+	&cli.StringSliceFlag{
+		Name:    "url",
+		Aliases: []string{"u"},
+		Usage:   "specify remote snippet `URL` instead of selecting it. can be specified multiple times.",
+	},
+
+	// --id ID
+	&cli.StringSliceFlag{
+		Name:  "id",
+		Usage: "specify remote snippet `ID` instead of selecting it. can be specified multiple times.",
+	},
+
+	// --platform PLATFORM
+	&cli.StringFlag{
+		Name:  "platform",
+		Usage: "narrow down the snippets to the `PLATFORM` containing this string. ex) gist.github.com:user",
+	},
+
+	// -q REGEX
+	&cli.StringFlag{
+		Name:    "query",
+		Aliases: []string{"q"},
+		Usage:   "select remote snippets whose line of url, platform and title matches the `REGEX`, instead of selecting it.",
+	},
+
+	// --all
+	&cli.BoolFlag{
+		Name:  "all",
+		Usage: "accept multiple snippets matched by --id/--query.",
+	},
+}
+
+// selectSnippetURL returns the URLs of target snippets.
+// If URLs are given as args or the --url/--id/--query flags are specified, the snippets are resolved
+// against the list without running the select command.
+func selectSnippetURL(c *cli.Context, selectCmd string, cl *client.Client, list client.SnippetList, args []string) (urlList []string, err error) {
+	// narrow down by platform
+	if platform := c.String("platform"); platform != "" {
+		list = list.Where(func(s *client.SnippetListData) bool {
+			return strings.Contains(s.Platform, platform)
+		})
+	}
+
+	urls := append(c.StringSlice("url"), args...)
+	ids := c.StringSlice("id")
+	query := c.String("query")
+
+	// select with the select command
+	if len(urls) == 0 && len(ids) == 0 && query == "" {
+		return runSnippetSelector(c, selectCmd, cl, list)
+	}
+
+	// resolve url
+	for _, u := range urls {
+		u = strings.TrimSuffix(u, "/")
+		l := list.Where(func(s *client.SnippetListData) bool {
+			return s.URL == u
+		})
+
+		if len(l) == 0 {
+			return urlList, fmt.Errorf("%w: %s", errNoSnippetMatched, u)
+		}
+
+		urlList = append(urlList, l[0].URL)
+	}
+
+	if len(ids) == 0 && query == "" {
+		return
+	}
+
+	// resolve id and query
+	var re *regexp.Regexp
+	if query != "" {
+		re, err = regexp.Compile(query)
+		if err != nil {
+			return urlList, withExitCode(ExitUsage, err)
+		}
+	}
+
+	matched := list.Where(func(s *client.SnippetListData) bool {
+		if len(ids) > 0 && !containsString(ids, s.Id) {
+			return false
+		}
+
+		if re != nil && !re.MatchString(createSelectLine(s)) {
+			return false
+		}
+
+		return true
+	})
+
+	switch {
+	case len(matched) == 0:
+		return urlList, errNoSnippetMatched
+	case len(matched) > 1 && !c.Bool("all"):
+		for _, m := range matched {
+			fmt.Fprint(os.Stderr, createSelectLine(m))
+		}
+		return urlList, fmt.Errorf("%w (%d snippets)", errMultipleSnippetMatched, len(matched))
+	}
+
+	for _, m := range matched {
+		urlList = append(urlList, m.URL)
+	}
+
+	return
+}
+
+// runSnippetSelector runs the select command against the snippet list and returns the selected URLs.
+// The contents of snippets are shown in the preview of the select command.
+func runSnippetSelector(c *cli.Context, selectCmd string, cl *client.Client, list client.SnippetList) (urlList []string, err error) {
+	// Create list
+	var filterText string
+	for _, l := range list {
+		filterText += createSelectLine(l)
+	}
+
+	// Run filter command
+	previewer := &snippetPreviewer{
+		ctx:        c.Context,
+		cl:         cl,
+		configFile: c.String("config"),
+	}
+	text, err := previewer.filter(selectCmd, filterText)
+	if err != nil {
+		return
+	}
+
+	for _, t := range text {
+		if t == "" {
+			continue
+		}
+
+		// generate url as search key value.
+		splitText := strings.Split(t, " ")
+		urlList = append(urlList, splitText[0])
+	}
+
+	return
+}
+
+// selectPlatform returns the platforms to create snippets.
+// If the --to flag is specified, the platform equal to or uniquely containing the value is selected without running the select command.
+func selectPlatform(c *cli.Context, selectCmd string, platformList []string) (result []string, err error) {
+	to := c.StringSlice("to")
+	if len(to) == 0 {
+		var filterText string
+		for _, p := range platformList {
+			t := fmt.Sprintln(p)
+			filterText += t
+		}
+
+		// Run filter command
+		text, ferr := filter(selectCmd, []string{}, filterText)
+		if ferr != nil {
+			return result, ferr
+		}
+
+		for _, t := range text {
+			if t != "" {
+				result = append(result, t)
+			}
+		}
+
+		return
+	}
+
+	for _, t := range to {
+		// exact match is preferred
+		if containsString(platformList, t) {
+			result = append(result, t)
+			continue
+		}
+
+		matched := []string{}
+		for _, p := range platformList {
+			if strings.Contains(p, t) {
+				matched = append(matched, p)
+			}
+		}
+
+		switch len(matched) {
+		case 0:
+			return result, newNotFoundError("platform not found: %s", t)
+		case 1:
+			result = append(result, matched[0])
+		default:
+			return result, newUsageError("multiple platforms matched: %s", strings.Join(matched, ", "))
+		}
+	}
+
+	return
+}
+
+// CommonFlagSelectPlatform ... --to
+var CommonFlagSelectPlatform = &cli.StringSliceFlag{
+	Name:  "to",
+	Usage: "specify the `PLATFORM` to create snippet, instead of selecting it. can be specified multiple times.",
+}
+
+// createSelectLine
+func createSelectLine(l *client.SnippetListData) string {
+	return fmt.Sprintln(l.URL, l.Platform, l.Title)
+}
+
+// containsString
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getSnippetTarget selects one snippet, and returns its url. args is the urls given as the arguments.
+func getSnippetTarget(c *cli.Context, args []string) (conf config.Config, cl client.Client, url string, err error) {
+	// Get **config data** and **client.Client**
+	conf, cl, err = clinetInit(c)
+	if err != nil {
+		return
+	}
+
+	// Get List
+	list, err := getSnippetList(c.Context, &cl, false, c.Bool("secret"))
+	if err != nil {
+		return
+	}
+
+	// Select target snippet
+	urlList, err := selectSnippetURL(c, conf.General.SelectCmd, &cl, list, args)
+	if err != nil {
+		return
+	}
+
+	if len(urlList) != 1 {
+		err = newUsageError("select one snippet")
+		return
+	}
+
+	return conf, cl, urlList[0], nil
+}
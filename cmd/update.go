@@ -7,7 +7,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"strings"
 
 	"github.com/blacknon/snipt/client"
 	"github.com/urfave/cli/v2"
@@ -15,10 +14,11 @@ import (
 
 // CmdUpdate
 var CmdUpdate = cli.Command{
-	Name:   "update",
-	Usage:  "update remote snippet data.",
-	Action: cmdActionUpdate,
-	Flags: []cli.Flag{
+	Name:      "update",
+	Usage:     "update remote snippet data.",
+	Action:    cmdActionUpdate,
+	ArgsUsage: "FILE...",
+	Flags: append([]cli.Flag{
 		// -f
 		CommonFlagSnippetFile,
 
@@ -30,15 +30,16 @@ var CmdUpdate = cli.Command{
 
 		// -s
 		CommonFlagViewSecret,
-	},
-}
 
-// TODO: renameの処理を実装する(オプション？)
+		// --confirm
+		diffFlagConfirm,
+	}, CommonFlagsSelect...),
+}
 
 func cmdActionUpdate(c *cli.Context) (err error) {
 	// check args count
 	if c.NArg() == 0 {
-		err = fmt.Errorf("no arguments")
+		err = newUsageError("no arguments")
 		c.App.OnUsageError(c, err, true)
 		return
 	}
@@ -57,24 +58,19 @@ func cmdActionUpdate(c *cli.Context) (err error) {
 	}
 
 	// Get **config data** and **client.Client**
-	cf := c.String("config")
-	conf, cl, err := clinetInit(cf)
+	conf, cl, err := clinetInit(c)
 	if err != nil {
 		return
 	}
 
 	// Get List
-	list := cl.List(c.Bool("file"), c.Bool("secret"))
-
-	// Create list
-	var filterText string
-	for _, l := range list {
-		t := fmt.Sprintln(l.URL, l.Platform, l.Title)
-		filterText += t
+	list, err := getSnippetList(c.Context, &cl, c.Bool("file"), c.Bool("secret"))
+	if err != nil {
+		return
 	}
 
-	// Run filter command
-	text, err := filter(conf.General.SelectCmd, []string{}, filterText)
+	// Select snippet
+	selectedList, err := selectSnippetURL(c, conf.General.SelectCmd, &cl, list, nil)
 	if err != nil {
 		return
 	}
@@ -83,15 +79,10 @@ func cmdActionUpdate(c *cli.Context) (err error) {
 	title := c.String("title")
 
 	urlList := []string{}
-	for _, t := range text {
-		// generate url as search key value.
-		splitText := strings.Split(t, " ")
-		url := splitText[0]
-
+	for _, url := range selectedList {
 		// Get SnippetData
-		snippetData, err := cl.Get(url)
+		snippetData, err := cl.Get(c.Context, url)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
 			return err
 		}
 
@@ -105,39 +96,39 @@ func cmdActionUpdate(c *cli.Context) (err error) {
 			// Get platorm visibility list
 			vl, eErr := cl.VisibilityListFromURL(url)
 			if eErr != nil {
-				return err
+				return eErr
 			}
 
 			// select visibility
 			visibility, eErr := getSelectVisibility(vl)
 			if eErr != nil {
-				return err
+				return eErr
 			}
 
 			snippetData.Visibility = visibility
 		}
 
-		// append file
-		files := []client.SnippetFileData{}
-		for _, f := range snippetFileDataList {
-			for _, sf := range snippetData.Files {
-				if f.Path == sf.Path {
-					files = append(files, f)
-				} else {
-					files = append(files, sf)
-				}
-			}
+		// replace files
+		files, missingPaths := replaceSnippetFiles(snippetData.Files, snippetFileDataList)
+		for _, p := range missingPaths {
+			fmt.Fprintf(os.Stderr, "Warning: %s is not in %s, skipped. use `add` subcommand to add it.\n", p, url)
+		}
+
+		// confirm the diff
+		if c.Bool("confirm") && !confirmSnippetDiff(url, snippetData.Files, files) {
+			continue
 		}
 		snippetData.Files = files
 
 		// update
-		rawURLs, err := cl.Update(url, snippetData)
+		snippets, err := cl.Update(c.Context, url, snippetData)
 		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
 			return err
 		}
 
-		urlList = append(urlList, rawURLs...)
+		for _, s := range snippets {
+			urlList = append(urlList, s.URL)
+		}
 	}
 
 	for _, u := range urlList {
@@ -146,3 +137,25 @@ func cmdActionUpdate(c *cli.Context) (err error) {
 
 	return
 }
+
+// replaceSnippetFiles replaces the files of the snippet with the local files of the same path.
+// The local files not in the snippet are not added, and their paths are returned as missingPaths.
+func replaceSnippetFiles(snippetFiles, localFiles []client.SnippetFileData) (files []client.SnippetFileData, missingPaths []string) {
+	index := map[string]int{}
+	for _, f := range snippetFiles {
+		index[f.Path] = len(files)
+		files = append(files, f)
+	}
+
+	for _, f := range localFiles {
+		i, ok := index[f.Path]
+		if !ok {
+			missingPaths = append(missingPaths, f.Path)
+			continue
+		}
+
+		files[i] = f
+	}
+
+	return
+}
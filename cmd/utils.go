@@ -6,6 +6,7 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -20,6 +21,7 @@ import (
 
 	"github.com/AlecAivazis/survey/v2"
 	"github.com/blacknon/snipt/client"
+	"github.com/blacknon/snipt/finder"
 )
 
 func askYesNo(s string) (result bool) {
@@ -118,6 +120,16 @@ func run(command string, r io.Reader, w io.Writer) error {
 }
 
 func filter(filter string, filterOptions []string, filterText string) (filteredData []string, err error) {
+	// use built-in finder
+	if isBuiltinSelectCmd(filter) {
+		if filterText == "" {
+			return
+		}
+
+		items := strings.Split(strings.TrimSuffix(filterText, "\n"), "\n")
+		return finder.Find(items)
+	}
+
 	//
 	var buf bytes.Buffer
 	selectCmd := fmt.Sprintf("%s %s", filter, strings.Join(filterOptions, " "))
@@ -125,6 +137,14 @@ func filter(filter string, filterOptions []string, filterText string) (filteredD
 	//
 	err = run(selectCmd, strings.NewReader(filterText), &buf)
 	if err != nil {
+		// fzf exits with 130 when the selection is cancelled, and with 1 when no item is matched.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && (exitErr.ExitCode() == 130 || exitErr.ExitCode() == 1) {
+			err = fmt.Errorf("%w: %s", finder.ErrCancelled, err)
+			return
+		}
+
+		err = fmt.Errorf("select command `%s` failed: %w", filter, err)
 		return
 	}
 
@@ -134,6 +154,18 @@ func filter(filter string, filterOptions []string, filterText string) (filteredD
 	return
 }
 
+// isBuiltinSelectCmd returns true if the built-in finder is used instead of select command.
+// The built-in finder is used when `selectcmd = "builtin"` or the command is not found.
+func isBuiltinSelectCmd(selectCmd string) bool {
+	fields := strings.Fields(selectCmd)
+	if len(fields) == 0 || fields[0] == "builtin" {
+		return true
+	}
+
+	_, err := exec.LookPath(fields[0])
+	return err != nil
+}
+
 // write
 func write(w *os.File, data []byte) (err error) {
 	// write file
@@ -10,6 +10,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
@@ -22,12 +23,20 @@ type Config struct {
 	General GeneralConfig  `toml:"General"`
 	Gist    []GistConfig   `toml:"Gist"`
 	GitLab  []GitLabConfig `toml:"GitLab"`
+	Gitea   []GiteaConfig  `toml:"Gitea"`
+	Local   []LocalConfig  `toml:"Local"`
+
+	Bitbucket []BitbucketConfig `toml:"Bitbucket"`
 }
 
+// DefaultCacheTTL is the default value of `cache_ttl`
+const DefaultCacheTTL = 5 * time.Minute
+
 // GeneralConfig is a struct of general config
 type GeneralConfig struct {
 	Editor    string `toml:"editor"`
 	SelectCmd string `toml:"selectcmd"`
+	CacheTTL  string `toml:"cache_ttl"`
 }
 
 func (generalCfg *GeneralConfig) SetDefault() {
@@ -43,15 +52,64 @@ func (generalCfg *GeneralConfig) SetDefault() {
 		}
 	}
 
+	// CacheTTL
+	if generalCfg.CacheTTL == "" {
+		generalCfg.CacheTTL = DefaultCacheTTL.String()
+	}
+
 	// SelectCmd
+	// if fzf is not installed, use the built-in finder.
 	if generalCfg.SelectCmd == "" {
-		generalCfg.SelectCmd = "fzf"
+		if isCommandAvailable("fzf") {
+			generalCfg.SelectCmd = "fzf"
+		} else {
+			generalCfg.SelectCmd = "builtin"
+		}
+	}
+}
+
+// GetCacheTTL returns `cache_ttl` as time.Duration. ex) "5m", "1h"
+func (generalCfg *GeneralConfig) GetCacheTTL() (ttl time.Duration, err error) {
+	if generalCfg.CacheTTL == "" {
+		return DefaultCacheTTL, nil
 	}
+
+	ttl, err = time.ParseDuration(generalCfg.CacheTTL)
+	if err != nil {
+		err = fmt.Errorf("invalid cache_ttl: %s", err)
+	}
+
+	return
+}
+
+// HTTPConfig is a struct of config for the connection of remote platforms.
+// It is embedded in the config of each platform.
+type HTTPConfig struct {
+	// ssl
+	Insecure   bool   `toml:"skip_ssl"`
+	CAFile     string `toml:"ca_file"`
+	ClientCert string `toml:"client_cert"`
+	ClientKey  string `toml:"client_key"`
+
+	// proxy
+	Proxy     string `toml:"proxy"`
+	ProxyUser string `toml:"proxy_user"`
+	ProxyPass string `toml:"proxy_pass"`
+	NoProxy   string `toml:"no_proxy"`
+
+	// retry
+	MaxAttempts int `toml:"max_attempts"`
 }
 
 // GistConfig is a struct of config for Gist
 type GistConfig struct {
+	// GitHub Enterprise Server. ex) https://github.example.com/api/v3/
+	Url       string `toml:"url"`
+	UploadUrl string `toml:"upload_url"`
+
 	AccessToken string `toml:"access_token"`
+
+	HTTPConfig
 }
 
 func (gistCfg *GistConfig) SetDefault() {
@@ -72,13 +130,9 @@ func (gistCfg *GistConfig) Check() (err error) {
 // GitLabConfig is a struct of config for GitLab Snippet
 type GitLabConfig struct {
 	Url         string `toml:"url"`
-	Insecure    bool   `toml:"skip_ssl"`
 	AccessToken string `toml:"access_token"`
 
-	// proxy
-	Proxy     string `toml:"proxy"`
-	ProxyUser string `toml:"proxy_user"`
-	ProxyPass string `toml:"proxy_pass"`
+	HTTPConfig
 }
 
 func (gitlabCfg *GitLabConfig) SetDefault() {
@@ -100,6 +154,75 @@ func (gitlabCfg *GitLabConfig) Check() (err error) {
 	return
 }
 
+// GiteaConfig is a struct of config for Gitea/Forgejo.
+// Snippets are stored in the repository `repo`.
+type GiteaConfig struct {
+	Url         string `toml:"url"`
+	AccessToken string `toml:"access_token"`
+	Repo        string `toml:"repo"`
+
+	HTTPConfig
+}
+
+func (giteaCfg *GiteaConfig) SetDefault() {
+	// Repo
+	if giteaCfg.Repo == "" {
+		giteaCfg.Repo = "snippets"
+	}
+}
+
+func (giteaCfg *GiteaConfig) Check() (err error) {
+	// Check Empty
+	if giteaCfg.Url == "" || giteaCfg.AccessToken == "" {
+		err = fmt.Errorf("")
+		return err
+	}
+
+	return
+}
+
+// BitbucketConfig is a struct of config for Bitbucket Cloud Snippets
+type BitbucketConfig struct {
+	Url         string `toml:"url"`
+	User        string `toml:"user"`
+	AccessToken string `toml:"access_token"`
+
+	HTTPConfig
+}
+
+func (bitbucketCfg *BitbucketConfig) SetDefault() {
+	// Url
+	if bitbucketCfg.Url == "" {
+		bitbucketCfg.Url = "https://api.bitbucket.org/2.0"
+	}
+}
+
+func (bitbucketCfg *BitbucketConfig) Check() (err error) {
+	// Check Empty
+	if bitbucketCfg.AccessToken == "" {
+		err = fmt.Errorf("")
+		return err
+	}
+
+	return
+}
+
+// LocalConfig is a struct of config for local directory.
+type LocalConfig struct {
+	Path string `toml:"path"`
+	Git  bool   `toml:"git"`
+}
+
+func (localCfg *LocalConfig) SetDefault() {
+	// Path
+	if localCfg.Path == "" {
+		dir, err := GetDefaultConfigDir()
+		if err == nil {
+			localCfg.Path = filepath.Join(dir, "snippets")
+		}
+	}
+}
+
 // Load loads a config toml
 func (cfg *Config) Load(file string) error {
 	// Open file
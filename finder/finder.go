@@ -0,0 +1,525 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+// Package finder is a built-in fuzzy finder used when fzf/peco is not available.
+package finder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// ErrCancelled is returned when the selection is cancelled by the user.
+var ErrCancelled = errors.New("selection cancelled")
+
+// Finder
+type Finder struct {
+	// Items to select
+	Items []string
+
+	// Prompt shown in front of the query
+	Prompt string
+
+	// Multi enables multiple selection with Tab
+	Multi bool
+
+	// Preview returns the text shown in the preview pane for the item.
+	// It is called in the background and the result is cached, so it may take time.
+	Preview func(item string) string
+
+	in       *os.File
+	out      *os.File
+	query    []rune
+	matches  []match
+	cursor   int
+	offset   int
+	selected map[int]bool
+
+	// preview
+	previews       map[int]string
+	previewLoading map[int]bool
+	previewCh      chan previewResult
+	noDeadline     bool
+}
+
+// previewResult
+type previewResult struct {
+	index int
+	text  string
+}
+
+// Find runs the finder with default settings and returns the selected items.
+func Find(items []string) (selected []string, err error) {
+	f := &Finder{
+		Items:  items,
+		Prompt: "> ",
+		Multi:  true,
+	}
+
+	return f.Run()
+}
+
+// Run shows the finder on the terminal and returns the selected items.
+func (f *Finder) Run() (selected []string, err error) {
+	f.in, f.out, err = openTTY()
+	if err != nil {
+		return
+	}
+	defer f.in.Close()
+	defer f.out.Close()
+
+	// set terminal raw mode
+	fd := int(f.in.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return
+	}
+	defer term.Restore(fd, state)
+
+	// use alternate screen
+	fmt.Fprint(f.out, "\x1b[?1049h")
+	defer fmt.Fprint(f.out, "\x1b[?25h\x1b[?1049l")
+
+	f.selected = map[int]bool{}
+	f.previews = map[int]string{}
+	f.previewLoading = map[int]bool{}
+	f.previewCh = make(chan previewResult, 16)
+	f.update()
+
+	buf := make([]byte, 1024)
+	for {
+		f.requestPreview()
+		f.draw()
+
+		n, rerr := f.read(buf)
+		if rerr != nil {
+			return selected, rerr
+		}
+
+		for _, k := range parseKeys(buf[:n]) {
+			done, cancel := f.handleKey(k)
+			if cancel {
+				return nil, ErrCancelled
+			}
+
+			if done {
+				return f.result(), nil
+			}
+		}
+	}
+}
+
+// read reads the input from the terminal.
+// While the preview is loading, it returns periodically to receive the preview result.
+func (f *Finder) read(buf []byte) (n int, err error) {
+	if len(f.previewLoading) == 0 || f.noDeadline {
+		f.in.SetReadDeadline(time.Time{})
+		return f.in.Read(buf)
+	}
+
+	if err = f.in.SetReadDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
+		f.noDeadline = true
+		return f.in.Read(buf)
+	}
+
+	n, err = f.in.Read(buf)
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		err = nil
+	}
+
+	// receive preview results
+	for {
+		select {
+		case r := <-f.previewCh:
+			f.previews[r.index] = r.text
+			delete(f.previewLoading, r.index)
+		default:
+			return
+		}
+	}
+}
+
+// requestPreview starts loading the preview of the item under the cursor.
+func (f *Finder) requestPreview() {
+	if f.Preview == nil || len(f.matches) == 0 {
+		return
+	}
+
+	i := f.matches[f.cursor].index
+	if _, ok := f.previews[i]; ok || f.previewLoading[i] {
+		return
+	}
+
+	// terminal without deadline support can not wait for the result in background.
+	if f.noDeadline {
+		f.previews[i] = f.Preview(f.Items[i])
+		return
+	}
+
+	f.previewLoading[i] = true
+	go func() {
+		f.previewCh <- previewResult{index: i, text: f.Preview(f.Items[i])}
+	}()
+}
+
+// key
+type key struct {
+	code keyCode
+	r    rune
+}
+
+// keyCode
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyEnter
+	keyCancel
+	keyBackspace
+	keyClearLine
+	keyDeleteWord
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyTab
+	keyShiftTab
+	keyUnknown
+)
+
+// parseKeys
+func parseKeys(b []byte) (keys []key) {
+	for len(b) > 0 {
+		switch {
+		case bytes.HasPrefix(b, []byte("\x1b[A")), bytes.HasPrefix(b, []byte("\x1bOA")):
+			keys = append(keys, key{code: keyUp})
+			b = b[3:]
+		case bytes.HasPrefix(b, []byte("\x1b[B")), bytes.HasPrefix(b, []byte("\x1bOB")):
+			keys = append(keys, key{code: keyDown})
+			b = b[3:]
+		case bytes.HasPrefix(b, []byte("\x1b[Z")):
+			keys = append(keys, key{code: keyShiftTab})
+			b = b[3:]
+		case bytes.HasPrefix(b, []byte("\x1b[5~")):
+			keys = append(keys, key{code: keyPageUp})
+			b = b[4:]
+		case bytes.HasPrefix(b, []byte("\x1b[6~")):
+			keys = append(keys, key{code: keyPageDown})
+			b = b[4:]
+		case bytes.HasPrefix(b, []byte("\x1b[")), bytes.HasPrefix(b, []byte("\x1bO")):
+			// skip unsupported escape sequence
+			i := 2
+			for i < len(b) && (b[i] < 0x40 || b[i] > 0x7e) {
+				i++
+			}
+			keys = append(keys, key{code: keyUnknown})
+			b = b[min(i+1, len(b)):]
+		default:
+			r, size := utf8.DecodeRune(b)
+			b = b[size:]
+
+			switch r {
+			case '\r', '\n':
+				keys = append(keys, key{code: keyEnter})
+			case 0x1b, 0x03, 0x07: // Esc, Ctrl-C, Ctrl-G
+				keys = append(keys, key{code: keyCancel})
+			case 0x7f, 0x08: // Backspace, Ctrl-H
+				keys = append(keys, key{code: keyBackspace})
+			case 0x15: // Ctrl-U
+				keys = append(keys, key{code: keyClearLine})
+			case 0x17: // Ctrl-W
+				keys = append(keys, key{code: keyDeleteWord})
+			case 0x10, 0x0b: // Ctrl-P, Ctrl-K
+				keys = append(keys, key{code: keyUp})
+			case 0x0e: // Ctrl-N
+				keys = append(keys, key{code: keyDown})
+			case '\t':
+				keys = append(keys, key{code: keyTab})
+			default:
+				if r < 0x20 || r == utf8.RuneError {
+					keys = append(keys, key{code: keyUnknown})
+				} else {
+					keys = append(keys, key{code: keyRune, r: r})
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// handleKey
+func (f *Finder) handleKey(k key) (done, cancel bool) {
+	switch k.code {
+	case keyEnter:
+		return true, false
+	case keyCancel:
+		return false, true
+	case keyRune:
+		f.query = append(f.query, k.r)
+		f.update()
+	case keyBackspace:
+		if len(f.query) > 0 {
+			f.query = f.query[:len(f.query)-1]
+			f.update()
+		}
+	case keyClearLine:
+		f.query = f.query[:0]
+		f.update()
+	case keyDeleteWord:
+		i := len(f.query)
+		for i > 0 && f.query[i-1] == ' ' {
+			i--
+		}
+		for i > 0 && f.query[i-1] != ' ' {
+			i--
+		}
+		f.query = f.query[:i]
+		f.update()
+	case keyUp:
+		f.move(-1)
+	case keyDown:
+		f.move(1)
+	case keyPageUp:
+		f.move(-f.listHeight())
+	case keyPageDown:
+		f.move(f.listHeight())
+	case keyTab, keyShiftTab:
+		if f.Multi && len(f.matches) > 0 {
+			i := f.matches[f.cursor].index
+			f.selected[i] = !f.selected[i]
+		}
+
+		if k.code == keyTab {
+			f.move(1)
+		} else {
+			f.move(-1)
+		}
+	}
+
+	return false, false
+}
+
+// update filters the items with the current query.
+func (f *Finder) update() {
+	f.matches = filterItems(f.Items, string(f.query))
+	f.cursor = 0
+	f.offset = 0
+}
+
+// move
+func (f *Finder) move(n int) {
+	f.cursor += n
+	if f.cursor >= len(f.matches) {
+		f.cursor = len(f.matches) - 1
+	}
+	if f.cursor < 0 {
+		f.cursor = 0
+	}
+}
+
+// result
+func (f *Finder) result() (selected []string) {
+	for i, item := range f.Items {
+		if f.selected[i] {
+			selected = append(selected, item)
+		}
+	}
+
+	if len(selected) == 0 && len(f.matches) > 0 {
+		selected = append(selected, f.Items[f.matches[f.cursor].index])
+	}
+
+	return
+}
+
+// size
+func (f *Finder) size() (width, height int) {
+	width, height, err := term.GetSize(int(f.out.Fd()))
+	if err != nil {
+		return 80, 24
+	}
+
+	return
+}
+
+// listHeight
+func (f *Finder) listHeight() int {
+	_, height := f.size()
+	if height < 3 {
+		return 1
+	}
+
+	return height - 2
+}
+
+// draw
+func (f *Finder) draw() {
+	width, height := f.size()
+	listHeight := f.listHeight()
+
+	// split the screen when preview is enabled
+	fullWidth := width
+	if f.Preview != nil && width >= 40 {
+		width = width / 2
+	}
+
+	// scroll
+	if f.cursor < f.offset {
+		f.offset = f.cursor
+	}
+	if f.cursor >= f.offset+listHeight {
+		f.offset = f.cursor - listHeight + 1
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\x1b[?25l\x1b[H\x1b[2J")
+
+	// prompt line
+	buf.WriteString(truncate(f.Prompt+string(f.query), width))
+	buf.WriteString("\r\n")
+
+	// info line
+	info := fmt.Sprintf("  %d/%d", len(f.matches), len(f.Items))
+	if n := f.countSelected(); n > 0 {
+		info += fmt.Sprintf(" (%d)", n)
+	}
+	buf.WriteString("\x1b[2m" + truncate(info, width) + "\x1b[0m")
+
+	// item lines
+	for i := f.offset; i < len(f.matches) && i < f.offset+listHeight; i++ {
+		m := f.matches[i]
+		buf.WriteString("\r\n")
+
+		marker := "  "
+		if f.selected[m.index] {
+			marker = " *"
+		}
+
+		if i == f.cursor {
+			buf.WriteString("\x1b[1m>" + marker[1:])
+		} else {
+			buf.WriteString(marker)
+		}
+
+		buf.WriteString(highlight(f.Items[m.index], m.positions, width-2))
+		buf.WriteString("\x1b[0m")
+	}
+
+	// preview pane
+	if width < fullWidth {
+		f.drawPreview(&buf, width+1, fullWidth-width-1, height)
+	}
+
+	// move cursor to the end of prompt
+	fmt.Fprintf(&buf, "\x1b[1;%dH\x1b[?25h", min(utf8.RuneCountInString(f.Prompt)+len(f.query)+1, width))
+
+	f.out.Write(buf.Bytes())
+}
+
+// drawPreview draws the preview of the item under the cursor from the column col.
+func (f *Finder) drawPreview(buf *bytes.Buffer, col, width, height int) {
+	text := ""
+	if len(f.matches) > 0 {
+		i := f.matches[f.cursor].index
+		if f.previewLoading[i] {
+			text = "loading..."
+		} else {
+			text = f.previews[i]
+		}
+	}
+
+	lines := strings.Split(sanitize(text), "\n")
+	for row := 1; row <= height; row++ {
+		line := ""
+		if row-1 < len(lines) {
+			line = lines[row-1]
+		}
+
+		fmt.Fprintf(buf, "\x1b[%d;%dH\x1b[2m│\x1b[0m %s\x1b[K", row, col, truncate(line, width-2))
+	}
+}
+
+// countSelected
+func (f *Finder) countSelected() (n int) {
+	for _, s := range f.selected {
+		if s {
+			n++
+		}
+	}
+
+	return
+}
+
+// truncate
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if width < 0 {
+		width = 0
+	}
+	if len(r) > width {
+		r = r[:width]
+	}
+
+	return string(r)
+}
+
+// highlight returns s truncated to width with matched positions underlined.
+func highlight(s string, positions []int, width int) string {
+	pos := map[int]bool{}
+	for _, p := range positions {
+		pos[p] = true
+	}
+
+	var buf bytes.Buffer
+	for i, r := range []rune(truncate(s, width)) {
+		if pos[i] {
+			buf.WriteString("\x1b[4m" + string(r) + "\x1b[24m")
+		} else {
+			buf.WriteRune(r)
+		}
+	}
+
+	return buf.String()
+}
+
+// sanitize expands tabs and removes control characters from preview text.
+func sanitize(s string) string {
+	s = strings.ReplaceAll(s, "\t", "    ")
+
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\n' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// openTTY opens the terminal for interaction, because stdin/stdout may be redirected.
+func openTTY() (in, out *os.File, err error) {
+	inName, outName := "/dev/tty", "/dev/tty"
+	if runtime.GOOS == "windows" {
+		inName, outName = "CONIN$", "CONOUT$"
+	}
+
+	in, err = os.OpenFile(inName, os.O_RDWR, 0)
+	if err != nil {
+		return
+	}
+
+	out, err = os.OpenFile(outName, os.O_RDWR, 0)
+	if err != nil {
+		in.Close()
+	}
+
+	return
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package finder
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// match is an item matched with the query.
+type match struct {
+	index     int   // index of Finder.Items
+	score     int   // higher is better
+	positions []int // rune positions of matched characters
+}
+
+const (
+	scoreMatch       = 16
+	scoreConsecutive = 8
+	scoreBoundary    = 8
+	scoreGapPenalty  = 1
+)
+
+// filterItems returns the items matching all space separated terms of query, sorted by score.
+func filterItems(items []string, query string) (matches []match) {
+	terms := strings.Fields(query)
+
+	for i, item := range items {
+		m := match{index: i}
+
+		isMatch := true
+		for _, term := range terms {
+			score, positions, ok := fuzzyMatch([]rune(item), []rune(term))
+			if !ok {
+				isMatch = false
+				break
+			}
+
+			m.score += score
+			m.positions = append(m.positions, positions...)
+		}
+
+		if isMatch {
+			matches = append(matches, m)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	return
+}
+
+// fuzzyMatch matches the pattern to text as a subsequence.
+// It is case-insensitive unless pattern contains upper case characters (smart case).
+func fuzzyMatch(text, pattern []rune) (score int, positions []int, ok bool) {
+	if len(pattern) == 0 {
+		return 0, nil, true
+	}
+
+	caseSensitive := false
+	for _, r := range pattern {
+		if unicode.IsUpper(r) {
+			caseSensitive = true
+			break
+		}
+	}
+
+	equal := func(a, b rune) bool {
+		if caseSensitive {
+			return a == b
+		}
+		return unicode.ToLower(a) == unicode.ToLower(b)
+	}
+
+	// find the shortest window that ends with the first complete match, searching backward from its end.
+	// this prefers compact matches like fzf's v1 algorithm.
+	pi := 0
+	end := -1
+	for ti, r := range text {
+		if equal(r, pattern[pi]) {
+			pi++
+			if pi == len(pattern) {
+				end = ti
+				break
+			}
+		}
+	}
+
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	pi = len(pattern) - 1
+	start := end
+	for ti := end; ti >= 0; ti-- {
+		if equal(text[ti], pattern[pi]) {
+			pi--
+			if pi < 0 {
+				start = ti
+				break
+			}
+		}
+	}
+
+	// collect positions and calculate score
+	pi = 0
+	prev := -2
+	for ti := start; ti <= end && pi < len(pattern); ti++ {
+		if !equal(text[ti], pattern[pi]) {
+			continue
+		}
+
+		score += scoreMatch
+		if ti == prev+1 {
+			score += scoreConsecutive
+		}
+		if ti == 0 || isBoundary(text[ti-1]) {
+			score += scoreBoundary
+		}
+
+		positions = append(positions, ti)
+		prev = ti
+		pi++
+	}
+
+	score -= (end - start + 1 - len(pattern)) * scoreGapPenalty
+
+	return score, positions, true
+}
+
+// isBoundary
+func isBoundary(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
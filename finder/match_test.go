@@ -0,0 +1,93 @@
+// Copyright (c) 2023 Blacknon. All rights reserved.
+// Use of this source code is governed by an MIT license
+// that can be found in the LICENSE file.
+
+package finder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		pattern       string
+		wantOk        bool
+		wantPositions []int
+	}{
+		{"empty pattern", "abc", "", true, nil},
+		{"subsequence", "abcdef", "ace", true, []int{0, 2, 4}},
+		{"not match", "abcdef", "xyz", false, nil},
+		{"order", "abcdef", "ca", false, nil},
+		{"ignore case", "Hello World", "hw", true, []int{0, 6}},
+		{"smart case", "hello world", "Hw", false, nil},
+		{"shortest window", "a_b_ab", "ab", true, []int{0, 2}},
+		{"shortest window backward", "xaxxab", "ab", true, []int{4, 5}},
+		{"multibyte", "スニペット snippet", "ペsn", true, []int{2, 6, 7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, positions, ok := fuzzyMatch([]rune(tt.text), []rune(tt.pattern))
+			if ok != tt.wantOk {
+				t.Fatalf("fuzzyMatch() ok = %v, want %v", ok, tt.wantOk)
+			}
+
+			if !reflect.DeepEqual(positions, tt.wantPositions) {
+				t.Errorf("fuzzyMatch() positions = %v, want %v", positions, tt.wantPositions)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatchScore(t *testing.T) {
+	score := func(text, pattern string) int {
+		s, _, _ := fuzzyMatch([]rune(text), []rune(pattern))
+		return s
+	}
+
+	// consecutive characters score higher than scattered ones
+	if consecutive, scattered := score("xxsnipxx", "snip"), score("xsxnxixp", "snip"); consecutive <= scattered {
+		t.Errorf("consecutive score %d <= scattered score %d", consecutive, scattered)
+	}
+
+	// a match at the word boundary scores higher
+	if boundary, middle := score("foo-bar", "b"), score("foobar", "b"); boundary <= middle {
+		t.Errorf("boundary score %d <= middle score %d", boundary, middle)
+	}
+}
+
+func TestFilterItems(t *testing.T) {
+	items := []string{
+		"gist.github.com:user https://gist.github.com/user/1 docker compose",
+		"gitlab.com:user https://gitlab.com/-/snippets/2 jq one-liner",
+		"gist.github.com:user https://gist.github.com/user/3 jq select",
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"empty query matches all", "", []int{0, 1, 2}},
+		{"single term", "docker", []int{0}},
+		{"all terms must match", "jq select", []int{2}},
+		{"no match", "kubernetes", nil},
+		{"better match first", "sel", []int{2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int
+			for _, m := range filterItems(items, tt.query) {
+				got = append(got, m.index)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterItems(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
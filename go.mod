@@ -8,7 +8,9 @@ require (
 	github.com/google/go-github v17.0.0+incompatible
 	github.com/urfave/cli/v2 v2.27.2
 	github.com/xanzy/go-gitlab v0.103.0
+	golang.org/x/net v0.24.0
 	golang.org/x/oauth2 v0.19.0
+	golang.org/x/term v0.19.0
 )
 
 require (
@@ -23,9 +25,7 @@ require (
 	github.com/mgutz/ansi v0.0.0-20200706080929-d51e80ef957d // indirect
 	github.com/russross/blackfriday/v2 v2.1.0 // indirect
 	github.com/xrash/smetrics v0.0.0-20240312152122-5f08fbb34913 // indirect
-	golang.org/x/net v0.24.0 // indirect
 	golang.org/x/sys v0.19.0 // indirect
-	golang.org/x/term v0.19.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 	golang.org/x/time v0.5.0 // indirect
 	google.golang.org/appengine v1.6.8 // indirect